@@ -0,0 +1,519 @@
+// Package bcf reads BCF2.2 files, the binary encoding of vcf, into the Header and Feature types of the vcf package
+// https://samtools.github.io/hts-specs/BCFv2_qref.pdf
+//
+// The reader is read-only and decodes records in full, including their genotypes, into the same
+// text-backed fields that the vcf reader fills in. There is no support for BCF2.1 files, or for
+// querying regions through a .csi index. Floats are widened from 32 bits to their shortest decimal form.
+package bcf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+	"github.com/awilkey/bio-format-tools-go/vcf"
+)
+
+// Reader decodes the records of a bcf file into vcf Features
+type Reader struct {
+	Header *vcf.Header
+	buf    *bufio.Reader
+
+	// dictionaries that record fields index into
+	strings []string
+	contigs []string
+	samples int
+
+	// INFO IDs of Type Flag, which are given without a value
+	flags map[string]bool
+
+	closer io.Closer
+}
+
+// bcfMagic starts every bcf file, followed by its major and minor version
+var bcfMagic = []byte("BCF")
+
+// NewReader returns a Reader after decoding the bcf header. Input may be bgzf compressed, as bcf files
+// usually are, or uncompressed.
+func NewReader(r io.Reader) (*Reader, error) {
+	buf := bufio.NewReader(r)
+	if magic, _ := buf.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			return nil, err
+		}
+		buf = bufio.NewReader(gz)
+	}
+
+	magic := make([]byte, 5)
+	if _, err := io.ReadFull(buf, magic); err != nil || !bytes.HasPrefix(magic, bcfMagic) {
+		return nil, errors.New("not a bcf file")
+	}
+	if magic[3] != 2 || magic[4] != 2 {
+		return nil, fmt.Errorf("unsupported bcf version %d.%d", magic[3], magic[4])
+	}
+
+	var textLen uint32
+	if err := binary.Read(buf, binary.LittleEndian, &textLen); err != nil {
+		return nil, err
+	}
+	text, err := readBlock(buf, textLen)
+	if err != nil {
+		return nil, err
+	}
+	text = bytes.TrimRight(text, "\x00")
+	if !bytes.HasPrefix(text, []byte("#CHROM")) && !bytes.Contains(text, []byte("\n#CHROM")) {
+		return nil, errors.New("malformed bcf header: no #CHROM line")
+	}
+	vr, err := vcf.NewReader(bytes.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("malformed bcf header: %w", err)
+	}
+
+	br := &Reader{Header: vr.Header, buf: buf, samples: len(vr.Header.Genotypes)}
+	br.strings, br.contigs = dictionaries(vr.Header)
+	br.flags = make(map[string]bool)
+	for _, meta := range vr.Header.Infos {
+		if meta.Type == "Flag" {
+			br.flags[meta.Id] = true
+		}
+	}
+	return br, nil
+}
+
+// OpenReader opens the file at path and returns a reader for it. Close must be called to close the file.
+func OpenReader(path string) (*Reader, error) {
+	rc, err := fileio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br, err := NewReader(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	br.closer = rc
+	return br, nil
+}
+
+// Close closes the file opened by OpenReader. It does nothing for readers from NewReader.
+func (br *Reader) Close() error {
+	if br.closer == nil {
+		return nil
+	}
+	return br.closer.Close()
+}
+
+// dictionaries returns the string dictionary of FILTER, INFO and FORMAT IDs, and the contig dictionary,
+// in header order. PASS is always string 0, and IDX fields override the position of an ID.
+func dictionaries(h *vcf.Header) (strs, contigs []string) {
+	strs = []string{"PASS"}
+	seen := map[string]bool{"PASS": true}
+	for _, m := range h.MetaOrder {
+		meta, ok := m.(*vcf.Meta)
+		if !ok {
+			continue
+		}
+		switch meta.FieldType {
+		case "FILTER", "INFO", "FORMAT":
+			if idx, err := strconv.Atoi(meta.Optional["IDX"]); err == nil {
+				strs = setAt(strs, idx, meta.Id)
+			} else if !seen[meta.Id] {
+				strs = append(strs, meta.Id)
+			}
+			seen[meta.Id] = true
+		case "contig":
+			if idx, err := strconv.Atoi(meta.Optional["IDX"]); err == nil {
+				contigs = setAt(contigs, idx, meta.Id)
+			} else {
+				contigs = append(contigs, meta.Id)
+			}
+		}
+	}
+	return strs, contigs
+}
+
+// setAt sets s[i] to v, growing s as needed
+func setAt(s []string, i int, v string) []string {
+	for len(s) <= i {
+		s = append(s, "")
+	}
+	s[i] = v
+	return s
+}
+
+// Read returns a pointer to the next Feature, or io.EOF once all records have been read
+func (br *Reader) Read() (*vcf.Feature, error) {
+	var lengths [8]byte
+	if n, err := io.ReadFull(br.buf, lengths[:]); err != nil {
+		if n == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	shared, err := readBlock(br.buf, binary.LittleEndian.Uint32(lengths[0:4]))
+	if err != nil {
+		return nil, err
+	}
+	indiv, err := readBlock(br.buf, binary.LittleEndian.Uint32(lengths[4:8]))
+	if err != nil {
+		return nil, err
+	}
+	return br.decode(shared, indiv)
+}
+
+// readBlock reads a block of n bytes. The buffer grows as the data arrives, rather than being
+// allocated up front, so that a corrupt length can't allocate more than the input holds.
+func readBlock(r io.Reader, n uint32) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != int(n) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// ReadAll returns a slice of pointers to Features. Like vcf.Reader.ReadAll, reaching the end of the
+// input is reported as io.EOF, so callers must treat io.EOF as success.
+func (br *Reader) ReadAll() (features []*vcf.Feature, err error) {
+	for {
+		feature, err := br.Read()
+		if feature != nil {
+			features = append(features, feature)
+		}
+		if err != nil {
+			return features, err
+		}
+	}
+}
+
+// decode builds a Feature from the shared and per-sample blocks of a record
+func (br *Reader) decode(shared, indiv []byte) (*vcf.Feature, error) {
+	d := &decoder{b: shared}
+	chrom := int(d.int32())
+	pos := d.int32()
+	d.int32() // rlen, which is implied by REF and INFO END
+	qual := d.uint32()
+	infoAllele := d.uint32()
+	fmtSample := d.uint32()
+	nInfo, nAllele := int(infoAllele&0xFFFF), int(infoAllele>>16)
+	nSample, nFmt := int(fmtSample&0xFFFFFF), int(fmtSample>>24)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if chrom < 0 || chrom >= len(br.contigs) {
+		return nil, fmt.Errorf("contig index %d not in header", chrom)
+	}
+	if nFmt > 0 && nSample != br.samples {
+		return nil, fmt.Errorf("record has %d samples, header has %d", nSample, br.samples)
+	}
+
+	feat := &vcf.Feature{
+		Chrom:      br.contigs[chrom],
+		Pos:        uint64(pos) + 1,
+		Qual:       vcf.MissingQualField,
+		QualFormat: 'f',
+	}
+	if qual != floatMissing {
+		feat.Qual = shortFloat(math.Float32frombits(qual))
+	}
+
+	feat.Id = d.typedString()
+	if feat.Id == "" {
+		feat.Id = "."
+	}
+	for i := 0; i < nAllele; i++ {
+		if i == 0 {
+			feat.Ref = d.typedString()
+		} else {
+			feat.Alt = append(feat.Alt, d.typedString())
+		}
+	}
+	if len(feat.Alt) == 0 {
+		feat.Alt = []string{"."}
+	}
+
+	filters := d.typedInts()
+	feat.Filter = "."
+	if len(filters) > 0 {
+		names := make([]string, len(filters))
+		for i, idx := range filters {
+			names[i] = br.dictString(idx, d)
+		}
+		feat.Filter = strings.Join(names, ";")
+	}
+
+	feat.Info = make(map[string]string, nInfo)
+	feat.InfoOrder = make(map[string]int, nInfo)
+	feat.Flags = make(map[string]bool)
+	for i := 0; i < nInfo; i++ {
+		keys := d.typedInts()
+		if len(keys) != 1 {
+			d.fail(errors.New("malformed INFO key"))
+			break
+		}
+		key := br.dictString(keys[0], d)
+		typ, n := d.typeDescriptor()
+		vals := d.values(typ, n)
+		if typ == typeNull || n == 0 || br.flags[key] {
+			feat.Info[key] = key
+			feat.Flags[key] = true
+		} else {
+			feat.Info[key] = vals
+		}
+		feat.InfoOrder[key] = i
+	}
+	if nInfo == 0 {
+		feat.Info["."], feat.InfoOrder["."], feat.Flags["."] = ".", 0, true
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if nFmt == 0 {
+		return feat, nil
+	}
+	d = &decoder{b: indiv}
+	feat.Format = make(map[string]int, nFmt)
+	samples := make([][]string, nSample)
+	for i := 0; i < nFmt; i++ {
+		keys := d.typedInts()
+		if len(keys) != 1 {
+			d.fail(errors.New("malformed FORMAT key"))
+			break
+		}
+		key := br.dictString(keys[0], d)
+		feat.Format[key] = i
+		typ, n := d.typeDescriptor()
+		for s := range samples {
+			var val string
+			if key == "GT" {
+				val = d.genotype(typ, n)
+			} else {
+				val = d.values(typ, n)
+			}
+			if val == "" {
+				val = "."
+			}
+			samples[s] = append(samples[s], val)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	feat.Genotypes = make([][]byte, nSample)
+	for s, fields := range samples {
+		feat.Genotypes[s] = []byte(strings.Join(fields, ":"))
+	}
+	return feat, nil
+}
+
+// dictString returns string idx of the dictionary, failing d if it isn't defined
+func (br *Reader) dictString(idx int, d *decoder) string {
+	if idx < 0 || idx >= len(br.strings) || br.strings[idx] == "" {
+		d.fail(fmt.Errorf("string index %d not in header", idx))
+		return ""
+	}
+	return br.strings[idx]
+}
+
+// bcf typed value types, from the low nibble of a type descriptor
+const (
+	typeNull  = 0
+	typeInt8  = 1
+	typeInt16 = 2
+	typeInt32 = 3
+	typeFloat = 5
+	typeChar  = 7
+)
+
+// bit patterns for missing and end-of-vector floats
+const (
+	floatMissing     = 0x7F800001
+	floatEndOfVector = 0x7F800002
+)
+
+// decoder reads little-endian typed values from a record block. The first error stops decoding.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// next returns the next n bytes of the block. Once decoding has failed it returns zeros,
+// at most 4 bytes of them, as no caller needs more than a single value.
+func (d *decoder) next(n int) []byte {
+	if d.err == nil && (n < 0 || n > len(d.b)) {
+		d.fail(errors.New("truncated bcf record"))
+	}
+	if d.err != nil {
+		return make([]byte, min(max(n, 0), 4))
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) uint32() uint32 {
+	return binary.LittleEndian.Uint32(d.next(4))
+}
+
+func (d *decoder) int32() int32 {
+	return int32(d.uint32())
+}
+
+// typeDescriptor returns the type and count of the next typed value, reading an overflowed count as needed
+func (d *decoder) typeDescriptor() (typ byte, n int) {
+	desc := d.next(1)[0]
+	typ, n = desc&0x0F, int(desc>>4)
+	if n == 15 {
+		counts := d.typedInts()
+		if len(counts) != 1 {
+			d.fail(errors.New("malformed typed value count"))
+			return typ, 0
+		}
+		n = counts[0]
+	}
+	if n < 0 {
+		d.fail(fmt.Errorf("negative typed value count %d", n))
+		return typ, 0
+	}
+	if size := typeSize(typ); n > len(d.b)/size {
+		d.fail(errors.New("truncated bcf record"))
+		return typ, 0
+	}
+	return typ, n
+}
+
+// typeSize returns the size in bytes of a single value of type typ
+func typeSize(typ byte) int {
+	switch typ {
+	case typeInt16:
+		return 2
+	case typeInt32, typeFloat:
+		return 4
+	}
+	return 1
+}
+
+// intValue reads one integer of the given type, reporting whether it is missing or ends the vector
+func (d *decoder) intValue(typ byte) (v int, missing, end bool) {
+	switch typ {
+	case typeInt8:
+		v = int(int8(d.next(1)[0]))
+		return v, v == math.MinInt8, v == math.MinInt8+1
+	case typeInt16:
+		v = int(int16(binary.LittleEndian.Uint16(d.next(2))))
+		return v, v == math.MinInt16, v == math.MinInt16+1
+	case typeInt32:
+		v = int(d.int32())
+		return v, v == math.MinInt32, v == math.MinInt32+1
+	}
+	d.fail(fmt.Errorf("unknown bcf integer type %d", typ))
+	return 0, true, false
+}
+
+// typedInts reads a typed integer vector, such as a dictionary key or the FILTER list
+func (d *decoder) typedInts() []int {
+	typ, n := d.typeDescriptor()
+	if typ == typeNull {
+		return nil
+	}
+	var ints []int
+	for i := 0; i < n; i++ {
+		v, missing, end := d.intValue(typ)
+		if !missing && !end {
+			ints = append(ints, v)
+		}
+	}
+	return ints
+}
+
+// typedString reads a typed character vector
+func (d *decoder) typedString() string {
+	typ, n := d.typeDescriptor()
+	return d.values(typ, n)
+}
+
+// values reads n values of type typ as vcf text, comma separated, with missing values as "."
+// and end-of-vector padding dropped. A vector of only missing values is read as ".".
+func (d *decoder) values(typ byte, n int) string {
+	switch typ {
+	case typeNull:
+		return ""
+	case typeChar:
+		return string(bytes.TrimRight(d.next(n), "\x00"))
+	}
+
+	vals := make([]string, 0, n)
+	allMissing := true
+	for i := 0; i < n; i++ {
+		val, missing, end := ".", false, false
+		if typ == typeFloat {
+			bits := d.uint32()
+			missing, end = bits == floatMissing, bits == floatEndOfVector
+			if !missing && !end {
+				val = strconv.FormatFloat(shortFloat(math.Float32frombits(bits)), 'g', -1, 64)
+			}
+		} else {
+			var v int
+			if v, missing, end = d.intValue(typ); !missing && !end {
+				val = strconv.Itoa(v)
+			}
+		}
+		if end {
+			continue
+		}
+		allMissing = allMissing && missing
+		vals = append(vals, val)
+	}
+	if allMissing {
+		return "."
+	}
+	return strings.Join(vals, ",")
+}
+
+// genotype reads the n alleles of a GT value, each encoded as (allele+1)<<1 | phased
+func (d *decoder) genotype(typ byte, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		v, missing, end := d.intValue(typ)
+		if missing || end {
+			continue
+		}
+		if b.Len() > 0 {
+			if v&1 == 1 {
+				b.WriteByte('|')
+			} else {
+				b.WriteByte('/')
+			}
+		}
+		if allele := v>>1 - 1; allele < 0 {
+			b.WriteByte('.')
+		} else {
+			b.WriteString(strconv.Itoa(allele))
+		}
+	}
+	return b.String()
+}
+
+// shortFloat widens a 32 bit float to the float64 with the same shortest decimal form, so 0.1 stays 0.1
+func shortFloat(f float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return v
+}
@@ -0,0 +1,266 @@
+package bcf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/awilkey/bio-format-tools-go/vcf"
+)
+
+const testHeader = `##fileformat=VCFv4.2
+##FILTER=<ID=PASS,Description="All filters passed">
+##FILTER=<ID=q10,Description="Quality below 10">
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Total Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele Frequency">
+##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+##FORMAT=<ID=DP,Number=1,Type=Integer,Description="Read Depth">
+##contig=<ID=19>
+##contig=<ID=20>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA00001	NA00002
+`
+
+// the same records as text, as the vcf reader would read them
+const testRecords = "20\t14370\trs6054257\tG\tA\t29\tPASS\tDP=14;AF=0.1;DB\tGT:DP\t0|0:1\t1/0:.\n" +
+	"20\t17330\t.\tT\tA,C\t.\tq10\t.\tGT\t./.\t1|2\n"
+
+// typed encodes a bcf typed value with fewer than 15 elements
+func typed(typ byte, n int, payload ...byte) []byte {
+	return append([]byte{byte(n)<<4 | typ}, payload...)
+}
+
+func typedString(s string) []byte {
+	return typed(typeChar, len(s), []byte(s)...)
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+// record encodes a bcf record from its fixed fields and typed blocks
+func record(chrom, pos int32, qual uint32, nInfo, nAllele, nFmt int, shared, indiv []byte) []byte {
+	var fixed []byte
+	fixed = append(fixed, le32(uint32(chrom))...)
+	fixed = append(fixed, le32(uint32(pos))...)
+	fixed = append(fixed, le32(1)...)
+	fixed = append(fixed, le32(qual)...)
+	fixed = append(fixed, le32(uint32(nAllele<<16|nInfo))...)
+	fixed = append(fixed, le32(uint32(nFmt<<24|2))...)
+	shared = append(fixed, shared...)
+
+	out := append(le32(uint32(len(shared))), le32(uint32(len(indiv)))...)
+	return append(append(out, shared...), indiv...)
+}
+
+// testBCF returns the uncompressed bcf encoding of testHeader and testRecords
+func testBCF() []byte {
+	b := []byte("BCF\x02\x02")
+	b = append(b, le32(uint32(len(testHeader)+1))...)
+	b = append(b, testHeader+"\x00"...)
+
+	// string dictionary: PASS=0 q10=1 DP=2 AF=3 DB=4 GT=5, contigs: 19=0 20=1
+	var shared, indiv []byte
+	shared = append(shared, typedString("rs6054257")...)
+	shared = append(shared, typedString("G")...)
+	shared = append(shared, typedString("A")...)
+	shared = append(shared, typed(typeInt8, 1, 0)...)
+	shared = append(shared, typed(typeInt8, 1, 2)...)
+	shared = append(shared, typed(typeInt8, 1, 14)...)
+	shared = append(shared, typed(typeInt8, 1, 3)...)
+	shared = append(shared, typed(typeFloat, 1, le32(math.Float32bits(0.1))...)...)
+	shared = append(shared, typed(typeInt8, 1, 4)...)
+	shared = append(shared, typed(typeNull, 0)...)
+	indiv = append(indiv, typed(typeInt8, 1, 5)...)
+	indiv = append(indiv, typed(typeInt8, 2, 0x02, 0x03, 0x04, 0x02)...)
+	indiv = append(indiv, typed(typeInt8, 1, 2)...)
+	indiv = append(indiv, typed(typeInt8, 1, 1, 0x80)...)
+	b = append(b, record(1, 14369, math.Float32bits(29), 3, 2, 2, shared, indiv)...)
+
+	shared, indiv = nil, nil
+	shared = append(shared, typed(typeChar, 0)...)
+	shared = append(shared, typedString("T")...)
+	shared = append(shared, typedString("A")...)
+	shared = append(shared, typedString("C")...)
+	shared = append(shared, typed(typeInt8, 1, 1)...)
+	indiv = append(indiv, typed(typeInt8, 1, 5)...)
+	indiv = append(indiv, typed(typeInt8, 2, 0x00, 0x00, 0x04, 0x07)...)
+	b = append(b, record(1, 17329, floatMissing, 0, 3, 1, shared, indiv)...)
+	return b
+}
+
+func TestRead(t *testing.T) {
+	vr, err := vcf.NewReader(strings.NewReader(testHeader + testRecords))
+	if err != nil {
+		t.Fatalf("vcf.NewReader() error: %v", err)
+	}
+	want, _ := vr.ReadAll()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(testBCF())
+	_ = zw.Close()
+
+	tests := []struct {
+		Name  string
+		Input []byte
+	}{{
+		Name:  "Uncompressed",
+		Input: testBCF(),
+	}, {
+		Name:  "Compressed",
+		Input: gz.Bytes(),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			br, err := NewReader(bytes.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			if !reflect.DeepEqual(br.Header.Genotypes, vr.Header.Genotypes) {
+				t.Errorf("NewReader() error: unexpected samples\ngot \t%v\nwant \t%v", br.Header.Genotypes, vr.Header.Genotypes)
+			}
+			features, err := br.ReadAll()
+			if err != io.EOF {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			if len(features) != len(want) {
+				t.Fatalf("ReadAll() error: unexpected count\ngot \t%d\nwant \t%d", len(features), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(features[i], want[i]) {
+					t.Errorf("Read() error: record %d\ngot \t%+v\nwant \t%+v", i, features[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input []byte
+		Error error
+	}{{
+		Name:  "NotBCF",
+		Input: []byte(testHeader),
+		Error: errors.New("not a bcf file"),
+	}, {
+		Name:  "Version",
+		Input: []byte("BCF\x02\x01\x00\x00\x00\x00"),
+		Error: errors.New("unsupported bcf version 2.1"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if _, err := NewReader(bytes.NewReader(tt.Input)); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("NewReader() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func TestRead_Corrupt(t *testing.T) {
+	header := append([]byte("BCF\x02\x02"), le32(uint32(len(testHeader)+1))...)
+	header = append(header, testHeader+"\x00"...)
+
+	tests := []struct {
+		Name  string
+		Input []byte
+		Error error
+	}{{
+		Name: "NegativeCount",
+		// an ID with an overflowed count of -5
+		Input: append(header, record(1, 0, floatMissing, 0, 0, 0, []byte{0xF7, 0x11, 0xFB}, nil)...),
+		Error: errors.New("negative typed value count -5"),
+	}, {
+		Name:  "CountPastBlock",
+		Input: append(header, record(1, 0, floatMissing, 0, 0, 0, []byte{0xF7, 0x13, 0xFF, 0xFF, 0xFF, 0x7F}, nil)...),
+		Error: errors.New("truncated bcf record"),
+	}, {
+		Name:  "BlockPastInput",
+		Input: append(header, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x01),
+		Error: io.ErrUnexpectedEOF,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			br, err := NewReader(bytes.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			if _, err := br.Read(); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Read() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func TestNewReader_MalformedHeader(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Error error
+	}{{
+		Name:  "Empty",
+		Input: "",
+		Error: errors.New("malformed bcf header: no #CHROM line"),
+	}, {
+		Name:  "Null",
+		Input: "\x00",
+		Error: errors.New("malformed bcf header: no #CHROM line"),
+	}, {
+		Name:  "NoCHROM",
+		Input: "##fileformat=VCFv4.2\n##contig=<ID=20>\n\x00",
+		Error: errors.New("malformed bcf header: no #CHROM line"),
+	}, {
+		Name:  "NotVCF",
+		Input: "##fileformat=BCFv2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n\x00",
+		Error: fmt.Errorf("malformed bcf header: %w", errors.New(`invalid fileformat "BCFv2"`)),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			input := append([]byte("BCF\x02\x02"), le32(uint32(len(tt.Input)))...)
+			input = append(input, tt.Input...)
+			if _, err := NewReader(bytes.NewReader(input)); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("NewReader() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	valid := testBCF()
+	f.Add(valid)
+	for _, n := range []int{5, 9, len(valid) / 2, len(valid) - 1} {
+		f.Add(valid[:n]) // truncated
+	}
+	for _, i := range []int{len(testHeader) + 10, len(valid) - 20, len(valid) - 5} {
+		corrupt := append([]byte(nil), valid...)
+		corrupt[i] ^= 0xFF
+		f.Add(corrupt)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		br, err := NewReader(bytes.NewReader(input))
+		if err != nil {
+			return
+		}
+		for i := 0; i < 100; i++ {
+			if _, err := br.Read(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,295 @@
+// Structured accessors for gff3 attributes with reserved meanings
+// http://www.sequenceontology.org/gff3.shtml
+
+package gff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// reservedTags are the gff3 attribute tags with predefined meanings
+var reservedTags = []string{"ID", "Name", "Alias", "Parent", "Target", "Gap", "Derives_from", "Note", "Dbxref", "Ontology_term", "Is_circular"}
+
+// attribute returns the value of the attribute tag key
+func (f *Feature) attribute(key string) (string, bool) {
+	val, ok := f.Attributes[key]
+	return val, ok
+}
+
+// canonicalTags renames tags differing only in case from a reserved tag, such as "id", to the
+// reserved tag, unless it is already present. Of several such tags, the first in sort order is kept.
+func canonicalTags(attributes map[string]string) {
+	for _, tag := range reservedTags {
+		if _, ok := attributes[tag]; ok {
+			continue
+		}
+		var found string
+		for key := range attributes {
+			if strings.EqualFold(key, tag) && (found == "" || key < found) {
+				found = key
+			}
+		}
+		if found != "" {
+			attributes[tag] = attributes[found]
+			delete(attributes, found)
+		}
+	}
+}
+
+// SetAttribute sets the attribute tag key to value, creating the attribute map if the feature has none.
+// value is given decoded, and is percent-encoded for storage, apart from commas, which separate
+// the values of multi-valued attributes.
+func (f *Feature) SetAttribute(key, value string) {
+	f.DeleteAttribute(key)
+	if f.Attributes == nil {
+		f.Attributes = make(map[string]string)
+	}
+	f.Attributes[key] = escape(value, ";=&", false)
+}
+
+// DeleteAttribute removes the attribute tag key. Deleting from a feature without attributes does nothing.
+func (f *Feature) DeleteAttribute(key string) {
+	delete(f.Attributes, key)
+}
+
+// ID returns the ID attribute of the feature, or "" if it has none
+func (f *Feature) ID() string {
+	val, _ := f.attribute("ID")
+	return val
+}
+
+// Name returns the Name attribute of the feature, or "" if it has none
+func (f *Feature) Name() string {
+	val, _ := f.attribute("Name")
+	return val
+}
+
+// Parents returns the IDs listed in the Parent attribute of the feature, or nil if it has none
+func (f *Feature) Parents() []string {
+	val, ok := f.attribute("Parent")
+	if !ok || val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// DerivesFrom returns the IDs listed in the Derives_from attribute of the feature, or nil if it has none.
+// Unlike Parent, Derives_from relates a feature to the features it was derived from rather than
+// those containing it, such as a mature peptide and its polyprotein.
+func (f *Feature) DerivesFrom() []string {
+	val, ok := f.attribute("Derives_from")
+	if !ok || val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// IsCircular reports whether the Is_circular attribute is "true", marking a circular landmark
+// such as a plasmid or mitochondrial genome. It is false when absent or any other value.
+func (f *Feature) IsCircular() bool {
+	val, _ := f.attribute("Is_circular")
+	return val == "true"
+}
+
+// OntologyTerms returns the decoded terms listed in the Ontology_term attribute, such as
+// SO:0000704 or GO:0046703, or nil if it has none. Terms that can't be decoded are returned as-is.
+func (f *Feature) OntologyTerms() []string {
+	val, ok := f.attribute("Ontology_term")
+	if !ok || val == "" {
+		return nil
+	}
+	terms := strings.Split(val, ",")
+	for i, term := range terms {
+		if decoded, err := unescape(term); err == nil {
+			terms[i] = decoded
+		}
+	}
+	return terms
+}
+
+// TargetAttr describes the alignment target of a feature, as given by the Target attribute.
+// Format is "target_id start end [strand]", where strand is optional.
+type TargetAttr struct {
+	ID     string
+	Start  uint64
+	End    uint64
+	Strand string
+}
+
+// Target returns the parsed Target attribute of the feature, and false if it is missing or malformed
+func (f *Feature) Target() (*TargetAttr, bool) {
+	val, ok := f.attribute("Target")
+	if !ok {
+		return nil, false
+	}
+
+	fields := strings.Fields(val)
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, false
+	}
+
+	var target TargetAttr
+	var err error
+	if target.ID, err = unescape(fields[0]); err != nil {
+		return nil, false
+	}
+	if target.Start, err = strconv.ParseUint(fields[1], 10, 64); err != nil || target.Start < 1 {
+		return nil, false
+	}
+	if target.End, err = strconv.ParseUint(fields[2], 10, 64); err != nil || target.End < target.Start {
+		return nil, false
+	}
+	if len(fields) == 4 {
+		if fields[3] != "+" && fields[3] != "-" {
+			return nil, false
+		}
+		target.Strand = fields[3]
+	}
+
+	return &target, true
+}
+
+// GapOp is a single operation of a Gap attribute alignment, such as M8 or D3.
+// Op is one of M (match), I (insert), D (delete), F (forward frameshift) or R (reverse frameshift).
+type GapOp struct {
+	Op     byte
+	Length uint64
+}
+
+// Gap returns the parsed operations of the Gap attribute of the feature,
+// and false if it is missing or contains any invalid operation
+func (f *Feature) Gap() ([]GapOp, bool) {
+	val, ok := f.attribute("Gap")
+	if !ok {
+		return nil, false
+	}
+
+	fields := strings.Fields(val)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	ops := make([]GapOp, len(fields))
+	for i, field := range fields {
+		if len(field) < 2 || strings.IndexByte("MIDFR", field[0]) == -1 {
+			return nil, false
+		}
+		length, err := strconv.ParseUint(field[1:], 10, 64)
+		if err != nil || length == 0 {
+			return nil, false
+		}
+		ops[i] = GapOp{Op: field[0], Length: length}
+	}
+
+	return ops, true
+}
+
+// escapeSeqid percent-encodes a seqid (column 1), which may only contain the characters
+// [a-zA-Z0-9.:^*$@!+_?-|] unescaped
+func escapeSeqid(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isSeqidChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperHex[c>>4])
+		b.WriteByte(upperHex[c&0x0F])
+	}
+	return b.String()
+}
+
+// isSeqidChar reports whether c may appear unescaped in a seqid
+func isSeqidChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(".:^*$@!+_?-|", c) != -1
+}
+
+// escapeColumn percent-encodes the source or type column, in which only control characters
+// and percent signs must be escaped
+func escapeColumn(s string) string {
+	return escape(s, "", false)
+}
+
+// escapeAttributeKey percent-encodes an attribute tag. Tags can't contain any of the
+// column 9 reserved characters, commas included, or any control character.
+func escapeAttributeKey(s string) string {
+	return escape(s, ";=&,", false)
+}
+
+// escapeAttributeValue percent-encodes an attribute value as required by the gff3 spec:
+// control characters (tab and newline included), percent signs and the reserved characters ";=&".
+// Commas are left as-is, as they separate the values of multi-valued attributes, and existing
+// %XX escapes are preserved so that already-encoded values are not encoded twice.
+func escapeAttributeValue(s string) string {
+	return escape(s, ";=&", true)
+}
+
+// EncodeAttributeValue percent-encodes a single raw attribute value for column 9, escaping control
+// characters, percent signs, the reserved characters ";=&" and commas, so that it can be joined with
+// others by commas. Unlike the writer, existing %XX sequences are encoded too, as s is taken to be raw.
+func EncodeAttributeValue(s string) string {
+	return escape(s, ";=&,", false)
+}
+
+// DecodeAttributeValue decodes the %XX escapes of an attribute value, returning strconv.ErrSyntax
+// for a malformed escape such as %GG
+func DecodeAttributeValue(s string) (string, error) {
+	return unescape(s)
+}
+
+// escape percent-encodes control characters, '%', and any character in reserved.
+// If keepEscapes is set, a '%' that begins a valid %XX escape is left untouched.
+func escape(s string, reserved string, keepEscapes bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && keepEscapes && isEscape(s[i:]):
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7F || c == '%' || strings.IndexByte(reserved, c) != -1:
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&0x0F])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// isEscape reports whether s begins with a %XX hex escape
+func isEscape(s string) bool {
+	return len(s) >= 3 && s[0] == '%' && strings.IndexByte(upperHex+"abcdef", s[1]) != -1 && strings.IndexByte(upperHex+"abcdef", s[2]) != -1
+}
+
+// unescape decodes %XX hex escapes in a gff3 column or attribute value
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '%') == -1 {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", strconv.ErrSyntax
+		}
+		c, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", strconv.ErrSyntax
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,361 @@
+package gff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFeature_Target(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output *TargetAttr
+		Ok     bool
+	}{{
+		Name:   "Full",
+		Input:  "EST23 1 100 +",
+		Output: &TargetAttr{ID: "EST23", Start: 1, End: 100, Strand: "+"},
+		Ok:     true,
+	}, {
+		Name:   "NoStrand",
+		Input:  "EST23 5 21",
+		Output: &TargetAttr{ID: "EST23", Start: 5, End: 21},
+		Ok:     true,
+	}, {
+		Name:   "EscapedID",
+		Input:  "Contig%201 5 21 -",
+		Output: &TargetAttr{ID: "Contig 1", Start: 5, End: 21, Strand: "-"},
+		Ok:     true,
+	}, {
+		Name:  "BadStrand",
+		Input: "EST23 1 100 x",
+	}, {
+		Name:  "EndBeforeStart",
+		Input: "EST23 100 1 +",
+	}, {
+		Name:  "NotNumber",
+		Input: "EST23 one 100 +",
+	}, {
+		Name:  "TooFewFields",
+		Input: "EST23 1",
+	}, {
+		Name:  "BadEscape",
+		Input: "EST%2 1 100",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Attributes: map[string]string{"Target": tt.Input}}
+			out, ok := f.Target()
+			if ok != tt.Ok {
+				t.Errorf("Target() error: unexpected ok\ngot \t%v\nwant \t%v", ok, tt.Ok)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("Target() error: unexpected value\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_Gap(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output []GapOp
+		Ok     bool
+	}{{
+		Name:   "Full",
+		Input:  "M8 D3 M6 I1 M6",
+		Output: []GapOp{{'M', 8}, {'D', 3}, {'M', 6}, {'I', 1}, {'M', 6}},
+		Ok:     true,
+	}, {
+		Name:   "Frameshift",
+		Input:  "M3 F1 M3 R2",
+		Output: []GapOp{{'M', 3}, {'F', 1}, {'M', 3}, {'R', 2}},
+		Ok:     true,
+	}, {
+		Name:  "BadOp",
+		Input: "M8 X3 M6",
+	}, {
+		Name:  "MissingLength",
+		Input: "M8 D M6",
+	}, {
+		Name:  "ZeroLength",
+		Input: "M8 D0",
+	}, {
+		Name:  "Empty",
+		Input: "",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Attributes: map[string]string{"Gap": tt.Input}}
+			out, ok := f.Gap()
+			if ok != tt.Ok {
+				t.Errorf("Gap() error: unexpected ok\ngot \t%v\nwant \t%v", ok, tt.Ok)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("Gap() error: unexpected value\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
+
+func TestEscapeAttribute(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Key   string
+		Value string
+	}{{
+		Name:  "Plain",
+		Input: "gene:ENSG00000186092",
+		Key:   "gene:ENSG00000186092",
+		Value: "gene:ENSG00000186092",
+	}, {
+		Name:  "URL",
+		Input: "http://www.sequenceontology.org/gff3.shtml",
+		Key:   "http://www.sequenceontology.org/gff3.shtml",
+		Value: "http://www.sequenceontology.org/gff3.shtml",
+	}, {
+		Name:  "Reserved",
+		Input: "a;b=c&d,e",
+		Key:   "a%3Bb%3Dc%26d%2Ce",
+		Value: "a%3Bb%3Dc%26d,e",
+	}, {
+		Name:  "Control",
+		Input: "tab\there\nnewline\r",
+		Key:   "tab%09here%0Anewline%0D",
+		Value: "tab%09here%0Anewline%0D",
+	}, {
+		Name:  "Percent",
+		Input: "100% match %3B",
+		Key:   "100%25 match %253B",
+		Value: "100%25 match %3B",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := escapeAttributeKey(tt.Input); got != tt.Key {
+				t.Errorf("escapeAttributeKey() error:\ngot \t%v\nwant \t%v", got, tt.Key)
+			}
+			if got := escapeAttributeValue(tt.Input); got != tt.Value {
+				t.Errorf("escapeAttributeValue() error:\ngot \t%v\nwant \t%v", got, tt.Value)
+			}
+		})
+	}
+}
+
+func TestEncodeAttributeValue(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output string
+	}{{
+		Name:   "Plain",
+		Input:  "gene:ENSG00000186092",
+		Output: "gene:ENSG00000186092",
+	}, {
+		Name:   "Semicolon",
+		Input:  "fused; partial",
+		Output: "fused%3B partial",
+	}, {
+		Name:   "Comma",
+		Input:  "a,b",
+		Output: "a%2Cb",
+	}, {
+		Name:   "Equals",
+		Input:  "x=y&z",
+		Output: "x%3Dy%26z",
+	}, {
+		Name:   "Percent",
+		Input:  "100% %3B",
+		Output: "100%25 %253B",
+	}, {
+		Name:   "Control",
+		Input:  "tab\there\n",
+		Output: "tab%09here%0A",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			encoded := EncodeAttributeValue(tt.Input)
+			if encoded != tt.Output {
+				t.Errorf("EncodeAttributeValue() error:\ngot \t%v\nwant \t%v", encoded, tt.Output)
+			}
+			decoded, err := DecodeAttributeValue(encoded)
+			if err != nil || decoded != tt.Input {
+				t.Errorf("DecodeAttributeValue() error: round trip\ngot \t%q, %v\nwant \t%q", decoded, err, tt.Input)
+			}
+		})
+	}
+}
+
+func TestDecodeAttributeValue(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output string
+		Error  error
+	}{{
+		Name:   "Escapes",
+		Input:  "a%3Bb%3dc%2C%25",
+		Output: "a;b=c,%",
+	}, {
+		Name:  "BadHex",
+		Input: "a%GGb",
+		Error: strconv.ErrSyntax,
+	}, {
+		Name:  "Truncated",
+		Input: "a%3",
+		Error: strconv.ErrSyntax,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := DecodeAttributeValue(tt.Input)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("DecodeAttributeValue() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if out != tt.Output {
+				t.Errorf("DecodeAttributeValue() error:\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_TypedAttributes(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Attributes  map[string]string
+		ID          string
+		FeatName    string
+		Parents     []string
+		DerivesFrom []string
+	}{{
+		Name:       "Exact",
+		Attributes: map[string]string{"ID": "mRNA1", "Name": "EDEN.1", "Parent": "gene1,gene2"},
+		ID:         "mRNA1",
+		FeatName:   "EDEN.1",
+		Parents:    []string{"gene1", "gene2"},
+	}, {
+		Name:       "LowercaseStrict",
+		Attributes: map[string]string{"id": "mRNA1", "name": "EDEN.1", "parent": "gene1"},
+	}, {
+		Name:        "DerivesFrom",
+		Attributes:  map[string]string{"ID": "peptide1", "Derives_from": "polyprotein1,polyprotein2"},
+		ID:          "peptide1",
+		DerivesFrom: []string{"polyprotein1", "polyprotein2"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Attributes: tt.Attributes}
+			if got := f.ID(); got != tt.ID {
+				t.Errorf("ID() error:\ngot \t%v\nwant \t%v", got, tt.ID)
+			}
+			if got := f.Name(); got != tt.FeatName {
+				t.Errorf("Name() error:\ngot \t%v\nwant \t%v", got, tt.FeatName)
+			}
+			if got := f.Parents(); !reflect.DeepEqual(got, tt.Parents) {
+				t.Errorf("Parents() error:\ngot \t%v\nwant \t%v", got, tt.Parents)
+			}
+			if got := f.DerivesFrom(); !reflect.DeepEqual(got, tt.DerivesFrom) {
+				t.Errorf("DerivesFrom() error:\ngot \t%v\nwant \t%v", got, tt.DerivesFrom)
+			}
+		})
+	}
+}
+
+func TestFeature_IsCircular(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Attributes map[string]string
+		Output     bool
+	}{{
+		Name:       "True",
+		Attributes: map[string]string{"Is_circular": "true"},
+		Output:     true,
+	}, {
+		Name:       "False",
+		Attributes: map[string]string{"Is_circular": "false"},
+	}, {
+		Name:       "Absent",
+		Attributes: map[string]string{"ID": "chrM"},
+	}, {
+		Name:       "Invalid",
+		Attributes: map[string]string{"Is_circular": "yes"},
+	}, {
+		Name:       "WrongCase",
+		Attributes: map[string]string{"Is_circular": "TRUE"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Attributes: tt.Attributes}
+			if got := f.IsCircular(); got != tt.Output {
+				t.Errorf("IsCircular() error: got %v want %v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_OntologyTerms(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  map[string]string
+		Output []string
+	}{{
+		Name:   "Single",
+		Input:  map[string]string{"Ontology_term": "SO:0000704"},
+		Output: []string{"SO:0000704"},
+	}, {
+		Name:   "Multiple",
+		Input:  map[string]string{"Ontology_term": "SO:0000704,GO:0046703"},
+		Output: []string{"SO:0000704", "GO:0046703"},
+	}, {
+		Name:   "Encoded",
+		Input:  map[string]string{"Ontology_term": "DB%3Bx:1,GO:0046703"},
+		Output: []string{"DB;x:1", "GO:0046703"},
+	}, {
+		Name:  "Absent",
+		Input: map[string]string{"ID": "gene1"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Attributes: tt.Input}
+			if got := f.OntologyTerms(); !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("OntologyTerms() error: unexpected value\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_SetAttribute(t *testing.T) {
+	// an 8 column line leaves Attributes nil
+	f, err := NewReader(strings.NewReader("ctg123\t.\tgene\t1000\t9000\t.\t+\t.\n")).Read()
+	if f == nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if f.Attributes != nil {
+		t.Fatalf("Read() error: unexpected attributes %v", f.Attributes)
+	}
+
+	f.DeleteAttribute("ID")
+	f.SetAttribute("ID", "gene1")
+	f.SetAttribute("Note", "fused; 5%")
+	f.SetAttribute("Parent", "a,b")
+	want := "ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Parent=a,b;Note=fused%3B 5%25"
+	if got := f.String(); got != want {
+		t.Errorf("SetAttribute() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+	if got := f.Parents(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Parents() error:\ngot \t%v\nwant \t%v", got, []string{"a", "b"})
+	}
+
+	f.DeleteAttribute("Note")
+	f.DeleteAttribute("Parent")
+	if want := map[string]string{"ID": "gene1"}; !reflect.DeepEqual(f.Attributes, want) {
+		t.Errorf("DeleteAttribute() error:\ngot \t%v\nwant \t%v", f.Attributes, want)
+	}
+}
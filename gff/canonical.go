@@ -0,0 +1,145 @@
+package gff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonicalize reads a gff3 file from in and writes it to out in a deterministic canonical form,
+// so that files written by different tools, or tool versions, can be diffed.
+//
+// In canonical form:
+//   - sequence-region pragmas are kept, sorted by seqid, and other comments and pragmas are dropped
+//   - features are sorted by seqid, start, end and type, then by the rest of the line
+//   - missing values are always written as ".", and the attributes column is always present
+//   - attributes are ordered ID first, then Parent, then the rest alphabetically
+//   - attribute values are decoded and re-encoded, so that equivalent encodings compare equal
+func Canonicalize(in io.Reader, out io.Writer) error {
+	gr := NewReader(in)
+	features, err := gr.ReadAll()
+	if err != io.EOF {
+		return fmt.Errorf("line %d: %v", gr.LineNumber, err)
+	}
+
+	lines := make([]string, len(features))
+	for i, f := range features {
+		lines[i] = canonicalString(f)
+	}
+	order := make([]int, len(features))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := features[order[i]], features[order[j]]
+		switch {
+		case a.Seqid != b.Seqid:
+			return a.Seqid < b.Seqid
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		case a.End != b.End:
+			return a.End < b.End
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		}
+		return lines[order[i]] < lines[order[j]]
+	})
+
+	bw := bufio.NewWriter(out)
+	if _, err := NewWriter(bw); err != nil {
+		return err
+	}
+	seqids := make([]string, 0, len(gr.SequenceRegions))
+	for seqid := range gr.SequenceRegions {
+		seqids = append(seqids, seqid)
+	}
+	sort.Strings(seqids)
+	for _, seqid := range seqids {
+		_, _ = fmt.Fprintln(bw, gr.SequenceRegions[seqid])
+	}
+	for _, i := range order {
+		_, _ = fmt.Fprintln(bw, lines[i])
+	}
+	return bw.Flush()
+}
+
+// canonicalString returns the canonical form of a feature line, as described by Canonicalize
+func canonicalString(f *Feature) string {
+	columns := []string{
+		missing(escapeSeqid(f.Seqid)),
+		missing(escapeColumn(f.Source)),
+		missing(escapeColumn(f.Type)),
+		formatPosition(f.Start),
+		formatPosition(f.End),
+		".",
+		missing(f.Strand),
+		f.Phase.String(),
+		canonicalAttributes(f.Attributes),
+	}
+	if !f.scoreMissing() {
+		columns[5] = strconv.FormatFloat(f.Score, 'e', -1, 64)
+	}
+	return strings.Join(append(columns, f.Extra...), "\t")
+}
+
+// missing returns "." for an empty column
+func missing(s string) string {
+	if s == "" {
+		return "."
+	}
+	return s
+}
+
+// canonicalAttributes returns column 9 with ID first, then Parent, then the remaining tags
+// in alphabetical order. Each comma-separated value is decoded and then fully re-encoded.
+func canonicalAttributes(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return "."
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sortAttributeKeys(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		vals := strings.Split(attributes[key], ",")
+		for j, val := range vals {
+			if decoded, err := unescape(val); err == nil {
+				val = decoded
+			}
+			vals[j] = escape(val, ";=&,", false)
+		}
+		pairs[i] = escapeAttributeKey(key) + "=" + strings.Join(vals, ",")
+	}
+	return strings.Join(pairs, ";")
+}
+
+// unranked is the attributeRank of tags without a fixed position
+const unranked = 2
+
+// attributeRank orders ID before Parent before any other tag
+func attributeRank(key string) int {
+	switch key {
+	case "ID":
+		return 0
+	case "Parent":
+		return 1
+	}
+	return unranked
+}
+
+// sortAttributeKeys sorts tags by attributeRank, then alphabetically
+func sortAttributeKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := attributeRank(keys[i]), attributeRank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+}
@@ -0,0 +1,45 @@
+package gff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	in, err := os.Open(filepath.Join("testdata", "canonical.gff3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	want, err := os.ReadFile(filepath.Join("testdata", "canonical.golden.gff3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := Canonicalize(in, &b); err != nil {
+		t.Fatalf("Canonicalize() error: %v", err)
+	}
+	if got := b.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("Canonicalize() error:\ngot \n%s \nwant \n%s", got, want)
+	}
+
+	// canonical form is a fixed point
+	var again bytes.Buffer
+	if err := Canonicalize(bytes.NewReader(want), &again); err != nil {
+		t.Fatalf("Canonicalize() error: %v", err)
+	}
+	if !bytes.Equal(again.Bytes(), want) {
+		t.Errorf("Canonicalize() error: not idempotent\ngot \n%s \nwant \n%s", again.Bytes(), want)
+	}
+}
+
+func TestCanonicalize_Error(t *testing.T) {
+	var b bytes.Buffer
+	err := Canonicalize(bytes.NewReader([]byte("ctg1\tEVM\tgene\n")), &b)
+	if err == nil || err.Error() != "line 1: wrong number of fields" {
+		t.Errorf("Canonicalize() error: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package gff
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+)
+
+// FormatVersion is the gff version written by Writer in its ##gff-version pragma
+const FormatVersion = "3.2.1"
+
+// Capabilities describes what a Reader has detected about its input, for debugging files that parse unexpectedly
+type Capabilities struct {
+	// Compression is "gzip" or "bgzf" for compressed files opened with OpenReader, and "none" otherwise
+	Compression string
+	// Version is the value of the ##gff-version pragma, or "" if there hasn't been one
+	Version string
+	// Pragmas lists the names of the pragmas read so far, such as gff-version and sequence-region, sorted
+	Pragmas []string
+}
+
+// Capabilities reports what the reader has detected about its input. Pragmas are found as features
+// are read, so the report only covers the lines read so far.
+func (gr *Reader) Capabilities() Capabilities {
+	pragmas := make([]string, 0, len(gr.pragmas))
+	for name := range gr.pragmas {
+		pragmas = append(pragmas, name)
+	}
+	sort.Strings(pragmas)
+	return Capabilities{Compression: fileio.Compression(gr.r), Version: gr.Version, Pragmas: pragmas}
+}
+
+// recordPragma notes the name of a ## pragma line for Capabilities
+func (gr *Reader) recordPragma(line []byte) {
+	name := bytes.TrimPrefix(line, []byte("##"))
+	if end := bytes.IndexAny(name, " \t\r\n"); end != -1 {
+		name = name[:end]
+	}
+	if len(name) == 0 {
+		return
+	}
+	if gr.pragmas == nil {
+		gr.pragmas = make(map[string]bool)
+	}
+	gr.pragmas[string(name)] = true
+}
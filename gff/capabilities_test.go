@@ -0,0 +1,19 @@
+package gff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_Capabilities(t *testing.T) {
+	input := "##gff-version 3\n##sequence-region ctg123 1 1497228\n##sequence-region ctg124 1 1000\n" +
+		"ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene00001\n###\n"
+	r := NewReader(strings.NewReader(input))
+	_, _ = r.ReadAll()
+
+	want := Capabilities{Compression: "none", Version: "3", Pragmas: []string{"gff-version", "sequence-region"}}
+	if got := r.Capabilities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() error:\ngot \t%+v\nwant \t%+v", got, want)
+	}
+}
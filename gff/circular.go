@@ -0,0 +1,51 @@
+// Coordinate helpers for features on circular molecules, such as plasmids and organelle genomes
+
+package gff
+
+// A feature that wraps the origin of a circular molecule of length molLen may be given either
+// with Start > End (e.g. Start=4900 End=100 on a 5000bp plasmid) or, as the gff3 spec
+// suggests, with End extending past the end of the molecule (e.g. Start=4900 End=5100).
+// Both conventions are accepted by the helpers below, and describe the same 201bp feature.
+
+// interval is a closed, one-based range on a molecule
+type interval struct {
+	start, end uint64
+}
+
+// circularIntervals splits a feature into the one or two intervals it covers on a circular molecule
+func circularIntervals(f *Feature, molLen uint64) []interval {
+	switch {
+	case f.Start > f.End:
+		return []interval{{f.Start, molLen}, {1, f.End}}
+	case f.End > molLen && f.Start <= molLen:
+		return []interval{{f.Start, molLen}, {1, f.End - molLen}}
+	default:
+		return []interval{{f.Start, f.End}}
+	}
+}
+
+// CircularLength returns the length of f on a circular molecule of length molLen,
+// accounting for features that wrap the origin
+func CircularLength(f *Feature, molLen uint64) uint64 {
+	var length uint64
+	for _, iv := range circularIntervals(f, molLen) {
+		length += iv.end - iv.start + 1
+	}
+	return length
+}
+
+// CircularOverlaps reports whether a and b share a seqid and overlap by at least one base
+// on a circular molecule of length molLen, accounting for features that wrap the origin
+func CircularOverlaps(a, b *Feature, molLen uint64) bool {
+	if a.Seqid != b.Seqid {
+		return false
+	}
+	for _, ia := range circularIntervals(a, molLen) {
+		for _, ib := range circularIntervals(b, molLen) {
+			if ia.start <= ib.end && ib.start <= ia.end {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package gff
+
+import (
+	"testing"
+)
+
+func TestCircular(t *testing.T) {
+	const molLen = 5000
+	tests := []struct {
+		Name     string
+		A        Feature
+		B        Feature
+		LengthA  uint64
+		Overlaps bool
+	}{{
+		Name:     "Linear",
+		A:        Feature{Seqid: "pBR322", Start: 100, End: 200},
+		B:        Feature{Seqid: "pBR322", Start: 150, End: 300},
+		LengthA:  101,
+		Overlaps: true,
+	}, {
+		Name:     "LinearApart",
+		A:        Feature{Seqid: "pBR322", Start: 100, End: 200},
+		B:        Feature{Seqid: "pBR322", Start: 201, End: 300},
+		LengthA:  101,
+		Overlaps: false,
+	}, {
+		Name:     "WrapStartAfterEnd",
+		A:        Feature{Seqid: "pBR322", Start: 4900, End: 100},
+		B:        Feature{Seqid: "pBR322", Start: 50, End: 60},
+		LengthA:  201,
+		Overlaps: true,
+	}, {
+		Name:     "WrapPastEnd",
+		A:        Feature{Seqid: "pBR322", Start: 4900, End: 5100},
+		B:        Feature{Seqid: "pBR322", Start: 50, End: 60},
+		LengthA:  201,
+		Overlaps: true,
+	}, {
+		Name:     "BothWrap",
+		A:        Feature{Seqid: "pBR322", Start: 4990, End: 5},
+		B:        Feature{Seqid: "pBR322", Start: 4995, End: 5010},
+		LengthA:  16,
+		Overlaps: true,
+	}, {
+		Name:     "WrapMiss",
+		A:        Feature{Seqid: "pBR322", Start: 4900, End: 100},
+		B:        Feature{Seqid: "pBR322", Start: 101, End: 4899},
+		LengthA:  201,
+		Overlaps: false,
+	}, {
+		Name:     "OtherSeqid",
+		A:        Feature{Seqid: "pBR322", Start: 4900, End: 100},
+		B:        Feature{Seqid: "chrM", Start: 50, End: 60},
+		LengthA:  201,
+		Overlaps: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := CircularLength(&tt.A, molLen); got != tt.LengthA {
+				t.Errorf("CircularLength() error:\ngot \t%v\nwant \t%v", got, tt.LengthA)
+			}
+			if got := CircularOverlaps(&tt.A, &tt.B, molLen); got != tt.Overlaps {
+				t.Errorf("CircularOverlaps() error:\ngot \t%v\nwant \t%v", got, tt.Overlaps)
+			}
+			if got := CircularOverlaps(&tt.B, &tt.A, molLen); got != tt.Overlaps {
+				t.Errorf("CircularOverlaps() error: not symmetric\ngot \t%v\nwant \t%v", got, tt.Overlaps)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package gff
+
+// Density counts features in fixed-size windows along each seqid, such as genes per Mb.
+// Each feature is counted once, in the window holding its start: window i of a seqid covers
+// the 1-based positions i*windowSize+1 to (i+1)*windowSize. Each seqid's slice runs up to the
+// last window with a feature in it. Features with a missing start are skipped, and a windowSize
+// of zero returns nil.
+func Density(features []*Feature, windowSize uint64) map[string][]uint64 {
+	if windowSize == 0 {
+		return nil
+	}
+	density := make(map[string][]uint64)
+	for _, f := range features {
+		if f.Start == MissingPositionField {
+			continue
+		}
+		window := (f.Start - 1) / windowSize
+		counts := density[f.Seqid]
+		for uint64(len(counts)) <= window {
+			counts = append(counts, 0)
+		}
+		counts[window]++
+		density[f.Seqid] = counts
+	}
+	return density
+}
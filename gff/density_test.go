@@ -0,0 +1,49 @@
+package gff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDensity(t *testing.T) {
+	features := []*Feature{
+		{Seqid: "chr1", Start: 1, End: 50},
+		{Seqid: "chr1", Start: 100, End: 150}, // last base of the first window, ending in the second
+		{Seqid: "chr1", Start: 101, End: 120},
+		{Seqid: "chr1", Start: 350, End: 400},
+		{Seqid: "chr2", Start: 200, End: 210},
+		{Seqid: "chr2", Start: MissingPositionField, End: 10},
+	}
+
+	tests := []struct {
+		Name       string
+		WindowSize uint64
+		Output     map[string][]uint64
+	}{{
+		Name:       "Small",
+		WindowSize: 100,
+		Output: map[string][]uint64{
+			"chr1": {2, 1, 0, 1},
+			"chr2": {0, 1},
+		},
+	}, {
+		Name:       "Large",
+		WindowSize: 1000,
+		Output: map[string][]uint64{
+			"chr1": {4},
+			"chr2": {1},
+		},
+	}, {
+		Name:       "Zero",
+		WindowSize: 0,
+		Output:     nil,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if out := Density(features, tt.WindowSize); !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("Density() error:\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
@@ -19,7 +19,8 @@ import (
 type Feature struct {
 
 	// ID of the landmark used to establish the coordinate system of the feature.
-	// May contain any characters, but must escape anything not in [a-zA-Z0-9.:^*$@!+_?-|]
+	// May contain any characters, but must escape anything not in [a-zA-Z0-9.:^*$@!+_?-|].
+	// Seqid, Source and Type hold decoded values, and are escaped again when written.
 	Seqid string
 
 	// Free text qualifier intended to describe the algorithm
@@ -33,36 +34,122 @@ type Feature struct {
 
 	// The start of feature given in positive 1-based integer coordinates
 	// relative to the Seqid in column one.
-	// if "." treated as 0
+	// if "." treated as MissingPositionField
 	Start uint64
 
 	// The end of feature given in positive 1-based integer coordinates
 	// relative to the Seqid in column one. End must be larger than Start.
-	// uf "." treated as 0
+	// if "." treated as MissingPositionField
 	End uint64
 
 	// A floating point number.
 	// recommended to be an E-value or P-value
-	// if "." treated as math.MaxFloat64
+	// if "." treated as math.MaxFloat64, or as math.NaN() if read with Reader.MissingScoreAsNaN
 	Score float64
 
 	// Strand relative to landmark, one of [+,-,?]
 	Strand string
 
 	// For use with "CDS" type features, one of [0,1,2].
-	// if "." treated as 3
-	Phase int8
+	// if "." treated as PhaseNone
+	Phase Phase
 
 	// A semicolon separated list of <tag>=<value> pairs.
+	// Values are stored as they appear in the file, and any reserved
+	// characters are percent-encoded when the feature is written.
 	Attributes map[string]string
+
+	// Non-standard columns found after the ninth, if the Reader allowed them
+	Extra []string
 }
 
+// Columns 4 and 5 (start and end) allow for an undefined value "."
+const MissingPositionField = 0
+
 // Column s6 (score) allows for an undefined value "."
 const MissingScoreField = math.MaxFloat64
 
-// Column  8 (score) allows for an undefined value "."
+// scoreMissing reports whether the score is undefined, as either MissingScoreField or NaN
+func (f *Feature) scoreMissing() bool {
+	return f.Score == MissingScoreField || math.IsNaN(f.Score)
+}
+
+// Column  8 (phase) allows for an undefined value "."
 const MissingPhaseField = 3
 
+// Phase is the number of bases to remove from the start of a CDS feature to reach the next codon
+type Phase int8
+
+// Valid phases, along with PhaseNone for the undefined value "."
+const (
+	Phase0    Phase = 0
+	Phase1    Phase = 1
+	Phase2    Phase = 2
+	PhaseNone Phase = MissingPhaseField
+)
+
+// ParsePhase returns the Phase of a column 8 value, one of "0", "1", "2" or "."
+func ParsePhase(s string) (Phase, error) {
+	switch s {
+	case "0":
+		return Phase0, nil
+	case "1":
+		return Phase1, nil
+	case "2":
+		return Phase2, nil
+	case ".":
+		return PhaseNone, nil
+	}
+	return PhaseNone, fmt.Errorf("invalid phase %q", s)
+}
+
+// NewPhase returns the Phase for n, which must be 0, 1 or 2
+func NewPhase(n int) (Phase, error) {
+	if n < 0 || n > 2 {
+		return PhaseNone, fmt.Errorf("invalid phase %d", n)
+	}
+	return Phase(n), nil
+}
+
+// Valid reports whether p is one of the defined phases 0, 1 or 2
+func (p Phase) Valid() bool {
+	return p >= Phase0 && p <= Phase2
+}
+
+// String returns the column 8 representation of the phase, with anything but 0, 1 or 2 given as "."
+func (p Phase) String() string {
+	if !p.Valid() {
+		return "."
+	}
+	return strconv.Itoa(int(p))
+}
+
+// Clone returns a deep copy of the feature, so that its attributes can be changed
+// without affecting the original
+func (f *Feature) Clone() *Feature {
+	c := *f
+	if f.Attributes != nil {
+		c.Attributes = make(map[string]string, len(f.Attributes))
+		for key, val := range f.Attributes {
+			c.Attributes[key] = val
+		}
+	}
+	if f.Extra != nil {
+		c.Extra = append([]string(nil), f.Extra...)
+	}
+	return &c
+}
+
+// ScorePtr returns a pointer to a copy of the score, or nil if the score is undefined,
+// so that the MissingScoreField sentinel or NaN isn't mistaken for a real score
+func (f *Feature) ScorePtr() *float64 {
+	if f.scoreMissing() {
+		return nil
+	}
+	score := f.Score
+	return &score
+}
+
 // StartZero returns Feature.Start in zero based coordinate systems
 func (f *Feature) StartZero() uint64 {
 	return f.Start - 1
@@ -83,45 +170,84 @@ func (f *Feature) EndOne() uint64 {
 	return f.End
 }
 
-// String returns the string representation of the gff3 feature
+// String returns the string representation of the gff3 feature, with the ID and Parent tags first
+// and any other tags in sorted order
 func (f *Feature) String() string {
-	var start, end, score, phase, attributes string
-	start = strconv.FormatUint(f.Start, 10)
-	if start == "0" {
-		start = "."
-	}
+	return f.line(nil, true)
+}
 
-	end = strconv.FormatUint(f.End, 10)
-	if end == "0" {
-		end = "."
-	}
+// line returns the feature line, with the attributes ordered as described by Writer.AttributeOrder.
+// Unless ranked is set, the tags in order come first, ahead of ID and Parent.
+func (f *Feature) line(order []string, ranked bool) string {
+	var start, end, score, phase, attributes string
+	start = formatPosition(f.Start)
+	end = formatPosition(f.End)
 
-	if f.Score == MissingScoreField {
+	if f.scoreMissing() {
 		score = "."
 	} else {
 		score = strconv.FormatFloat(f.Score, 'e', -1, 64)
 	}
 
-	if p := strconv.Itoa(int(f.Phase)); p == strconv.Itoa(MissingPhaseField) {
-		phase = "."
+	phase = f.Phase.String()
+	seqid, source, typ := escapeSeqid(f.Seqid), escapeColumn(f.Source), escapeColumn(f.Type)
+
+	if len(f.Extra) > 0 { //Extra columns need the attributes column to be present
+		attributes = "."
+		if len(f.Attributes) > 0 {
+			attributes = f.attributeString(order, ranked)
+		}
+		return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", seqid, source, typ, start, end, score, f.Strand, phase, attributes, strings.Join(f.Extra, "\t"))
+	} else if len(f.Attributes) == 0 { //Attributes is an optional column
+		return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", seqid, source, typ, start, end, score, f.Strand, phase)
 	} else {
-		phase = p
+		attributes = f.attributeString(order, ranked)
+		return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", seqid, source, typ, start, end, score, f.Strand, phase, attributes)
+	}
+}
+
+// formatPosition returns a start or end coordinate, with MissingPositionField as "."
+func formatPosition(pos uint64) string {
+	if pos == MissingPositionField {
+		return "."
 	}
+	return strconv.FormatUint(pos, 10)
+}
 
-	if len(f.Attributes) == 0 { //Attributes is an optional column
-		return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", f.Seqid, f.Source, f.Type, start, end, score, f.Strand, phase)
+// attributeString returns the encoded column 9 of the feature, with the tags ranked by attributeRank
+// first if ranked is set, then the tags in order, then any others in sorted order
+func (f *Feature) attributeString(order []string, ranked bool) string {
+	b := new(bytes.Buffer)
+	var k []string
+	for key := range f.Attributes {
+		k = append(k, key)
+	}
+	if ranked {
+		sortAttributeKeys(k)
 	} else {
-		b := new(bytes.Buffer)
-		var k []string
-		for key := range f.Attributes {
-			k = append(k, key)
-		}
 		sort.Strings(k)
-		for _, key := range k {
-			_, _ = fmt.Fprintf(b, "%s=%s;", key, f.Attributes[key])
+	}
+
+	written := make(map[string]bool, len(k))
+	write := func(key string) {
+		val, ok := f.Attributes[key]
+		if !ok || written[key] {
+			return
+		}
+		written[key] = true
+		_, _ = fmt.Fprintf(b, "%s=%s;", escapeAttributeKey(key), escapeAttributeValue(val))
+	}
+	for _, key := range k {
+		if !ranked || attributeRank(key) == unranked {
+			break
 		}
-		attributes = b.String()
-		attributes = strings.TrimRight(attributes, ";")
-		return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", f.Seqid, f.Source, f.Type, start, end, score, f.Strand, phase, attributes)
+		write(key)
+	}
+	for _, key := range order {
+		write(key)
+	}
+	for _, key := range k {
+		write(key)
 	}
+	return strings.TrimRight(b.String(), ";")
 }
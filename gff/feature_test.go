@@ -1,8 +1,10 @@
 package gff
 
 import (
+	"errors"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -56,3 +58,172 @@ func TestFeature(t *testing.T) {
 		})
 	}
 }
+
+func TestFeature_Clone(t *testing.T) {
+	orig := Feature{
+		Seqid:      "Scaffold_102",
+		Source:     "EVM",
+		Type:       "CDS",
+		Start:      6452,
+		End:        6485,
+		Score:      1e+20,
+		Strand:     "+",
+		Phase:      2,
+		Attributes: map[string]string{"ID": "CDS705", "Parent": "mRNA906"},
+	}
+	want := Feature{
+		Seqid:      "Scaffold_102",
+		Source:     "EVM",
+		Type:       "CDS",
+		Start:      6452,
+		End:        6485,
+		Score:      1e+20,
+		Strand:     "+",
+		Phase:      2,
+		Attributes: map[string]string{"ID": "CDS705", "Parent": "mRNA906"},
+	}
+
+	clone := orig.Clone()
+	if !reflect.DeepEqual(*clone, orig) {
+		t.Errorf("Clone() error: unexpected copy\ngot \t%v\nwant \t%v", *clone, orig)
+	}
+
+	clone.Start = 1
+	clone.Attributes["ID"] = "CDS706"
+	clone.Attributes["Name"] = "clone"
+	delete(clone.Attributes, "Parent")
+	if !reflect.DeepEqual(orig, want) {
+		t.Errorf("Clone() error: original changed with clone\ngot \t%v\nwant \t%v", orig, want)
+	}
+}
+
+func TestPhase(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output Phase
+		String string
+		Valid  bool
+		Error  error
+	}{{
+		Name:   "Zero",
+		Input:  "0",
+		Output: Phase0,
+		String: "0",
+		Valid:  true,
+	}, {
+		Name:   "One",
+		Input:  "1",
+		Output: Phase1,
+		String: "1",
+		Valid:  true,
+	}, {
+		Name:   "Two",
+		Input:  "2",
+		Output: Phase2,
+		String: "2",
+		Valid:  true,
+	}, {
+		Name:   "Missing",
+		Input:  ".",
+		Output: PhaseNone,
+		String: ".",
+	}, {
+		Name:   "Three",
+		Input:  "3",
+		Output: PhaseNone,
+		String: ".",
+		Error:  errors.New("invalid phase \"3\""),
+	}, {
+		Name:   "NotNumber",
+		Input:  "x",
+		Output: PhaseNone,
+		String: ".",
+		Error:  errors.New("invalid phase \"x\""),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p, err := ParsePhase(tt.Input)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("ParsePhase() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if p != tt.Output || p.String() != tt.String || p.Valid() != tt.Valid {
+				t.Errorf("ParsePhase() error: unexpected phase\ngot \t%v %q %v\nwant \t%v %q %v", int8(p), p.String(), p.Valid(), int8(tt.Output), tt.String, tt.Valid)
+			}
+		})
+	}
+
+	if _, err := NewPhase(3); err == nil {
+		t.Errorf("NewPhase() error: accepted phase 3")
+	}
+	if p, err := NewPhase(1); err != nil || p != Phase1 {
+		t.Errorf("NewPhase() error: unexpected phase\ngot \t%v %v\nwant \t%v", p, err, Phase1)
+	}
+}
+
+func TestFeature_StringMissingPositions(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Start  uint64
+		End    uint64
+		Output string
+	}{{
+		Name:   "Both",
+		Start:  100,
+		End:    200,
+		Output: "ctg1\tEVM\tgene\t100\t200\t.\t+\t.",
+	}, {
+		Name:   "MissingStart",
+		End:    200,
+		Output: "ctg1\tEVM\tgene\t.\t200\t.\t+\t.",
+	}, {
+		Name:   "MissingEnd",
+		Start:  100,
+		Output: "ctg1\tEVM\tgene\t100\t.\t.\t+\t.",
+	}, {
+		Name:   "MissingBoth",
+		Output: "ctg1\tEVM\tgene\t.\t.\t.\t+\t.",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Seqid: "ctg1", Source: "EVM", Type: "gene", Start: tt.Start, End: tt.End, Score: MissingScoreField, Strand: "+", Phase: PhaseNone}
+			if got := f.String(); got != tt.Output {
+				t.Errorf("String() error:\ngot \t%q\nwant \t%q", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_ScorePtr(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output *float64
+	}{{
+		Name:  "Missing",
+		Input: ".",
+	}, {
+		Name:   "Present",
+		Input:  "0.5",
+		Output: func() *float64 { s := 0.5; return &s }(),
+	}, {
+		Name:   "Zero",
+		Input:  "0",
+		Output: new(float64),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader("ctg1\tEVM\tgene\t1\t100\t" + tt.Input + "\t+\t.\tID=gene1\n"))
+			f, err := r.Read()
+			if err != nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			if got := f.ScorePtr(); !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("ScorePtr() error: unexpected value\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
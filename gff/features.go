@@ -0,0 +1,36 @@
+package gff
+
+import (
+	"io"
+
+	"github.com/awilkey/bio-format-tools-go/util"
+)
+
+// Features is a collection of gff3 features
+type Features []*Feature
+
+// WriteTo writes each feature as a gff3 line to w, implementing io.WriterTo.
+// Only feature lines are written, use a Writer to include the gff3 header.
+func (fs Features) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, f := range fs {
+		n, err := io.WriteString(w, f.String()+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadFrom appends the features read from r until EOF, implementing io.ReaderFrom.
+// Reaching EOF is not treated as an error.
+func (fs *Features) ReadFrom(r io.Reader) (int64, error) {
+	cr := util.NewCountingReader(r)
+	features, err := NewReader(cr).ReadAll()
+	*fs = append(*fs, features...)
+	if err == io.EOF {
+		err = nil
+	}
+	return cr.Count(), err
+}
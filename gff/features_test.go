@@ -0,0 +1,55 @@
+package gff
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFeatures_RoundTrip(t *testing.T) {
+	in := Features{
+		{
+			Seqid:      "Scaffold_102",
+			Source:     "EVM",
+			Type:       "CDS",
+			Start:      6452,
+			End:        6485,
+			Score:      1e+20,
+			Strand:     "+",
+			Phase:      2,
+			Attributes: map[string]string{"ID": "CDS705", "Parent": "mRNA906"},
+		},
+		{
+			Seqid:      "Scaffold_103",
+			Source:     "EVM",
+			Type:       "gene",
+			Start:      100,
+			End:        2000,
+			Score:      math.MaxFloat64,
+			Strand:     "-",
+			Phase:      3,
+			Attributes: map[string]string{"ID": "gene1"},
+		},
+	}
+
+	pr, pw := io.Pipe()
+	written := make(chan int64, 1)
+	go func() {
+		n, err := in.WriteTo(pw)
+		_ = pw.CloseWithError(err)
+		written <- n
+	}()
+
+	var out Features
+	n, err := out.ReadFrom(pr)
+	if err != nil {
+		t.Fatalf("ReadFrom() error: %v", err)
+	}
+	if wn := <-written; wn != n {
+		t.Errorf("WriteTo() error: byte counts differ\nwrote \t%v\nread \t%v", wn, n)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("ReadFrom() error: unexpected features\ngot \t%v\nwant \t%v", out, in)
+	}
+}
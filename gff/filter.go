@@ -0,0 +1,113 @@
+package gff
+
+import (
+	"strings"
+)
+
+// Predicate reports whether a feature should be kept by Filter
+type Predicate func(*Feature) bool
+
+// FilterReader reads the features of a Reader that satisfy every one of a set of predicates
+type FilterReader struct {
+	r          *Reader
+	predicates []Predicate
+}
+
+// Filter returns a FilterReader over r, keeping only the features for which every predicate is true.
+// With no predicates every feature is kept.
+func Filter(r *Reader, predicates ...Predicate) *FilterReader {
+	return &FilterReader{r: r, predicates: predicates}
+}
+
+// Read returns the next feature that satisfies the predicates, skipping any others.
+// io.EOF is returned once the underlying reader is exhausted.
+func (fr *FilterReader) Read() (*Feature, error) {
+	for {
+		f, err := fr.r.Read()
+		if f != nil && fr.keep(f) {
+			return f, err
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ReadAll returns every remaining feature that satisfies the predicates, along with io.EOF on success
+func (fr *FilterReader) ReadAll() (features []*Feature, err error) {
+	for {
+		f, err := fr.Read()
+		if f != nil {
+			features = append(features, f)
+		}
+		if err != nil {
+			return features, err
+		}
+	}
+}
+
+func (fr *FilterReader) keep(f *Feature) bool {
+	for _, p := range fr.predicates {
+		if !p(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasType keeps features whose column 3 (type) is one of types
+func HasType(types ...string) Predicate {
+	return func(f *Feature) bool {
+		for _, typ := range types {
+			if f.Type == typ {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnStrand keeps features on strand, one of "+", "-", "." or "?"
+func OnStrand(strand string) Predicate {
+	return func(f *Feature) bool {
+		return f.Strand == strand
+	}
+}
+
+// HasAttribute keeps features with the attribute tag key, whatever its value
+func HasAttribute(key string) Predicate {
+	return func(f *Feature) bool {
+		_, ok := f.attribute(key)
+		return ok
+	}
+}
+
+// AttributeEquals keeps features whose attribute tag key has the decoded value, or has it as one
+// of its comma-separated values, as for Dbxref or Alias. Values are compared regardless of case
+// if caseInsensitive is set.
+func AttributeEquals(key, value string, caseInsensitive bool) Predicate {
+	equal := func(a string) bool {
+		if decoded, err := unescape(a); err == nil {
+			a = decoded
+		}
+		if caseInsensitive {
+			return strings.EqualFold(a, value)
+		}
+		return a == value
+	}
+	return func(f *Feature) bool {
+		val, ok := f.attribute(key)
+		if !ok {
+			return false
+		}
+		if equal(val) {
+			return true
+		}
+		for _, v := range strings.Split(val, ",") {
+			if equal(v) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,67 @@
+package gff
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	input := strings.Join([]string{
+		"ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Name=BRCA1;Dbxref=NCBI_Gene:672",
+		"ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1;Dbxref=RefSeq:NM_007294,HGNC:1100",
+		"ctg123\t.\tgene\t12000\t19000\t.\t-\t.\tID=gene2;Name=brca2",
+		"ctg123\t.\tgene\t22000\t29000\t.\t-\t.\tID=gene3;Name=Not%2CBRCA1",
+		"",
+	}, "\n")
+
+	tests := []struct {
+		Name       string
+		Predicates []Predicate
+		Output     []string
+	}{{
+		Name:   "None",
+		Output: []string{"gene1", "mRNA1", "gene2", "gene3"},
+	}, {
+		Name:       "HasAttributeAndType",
+		Predicates: []Predicate{HasAttribute("Dbxref"), HasType("gene")},
+		Output:     []string{"gene1"},
+	}, {
+		Name:       "HasAttributeAnyType",
+		Predicates: []Predicate{HasAttribute("Dbxref"), HasType("gene", "mRNA")},
+		Output:     []string{"gene1", "mRNA1"},
+	}, {
+		Name:       "AttributeEquals",
+		Predicates: []Predicate{AttributeEquals("Name", "BRCA1", false)},
+		Output:     []string{"gene1"},
+	}, {
+		Name:       "AttributeEqualsCaseInsensitive",
+		Predicates: []Predicate{AttributeEquals("Name", "BRCA2", true), OnStrand("-")},
+		Output:     []string{"gene2"},
+	}, {
+		Name:       "AttributeEqualsMultiValued",
+		Predicates: []Predicate{AttributeEquals("Dbxref", "HGNC:1100", false)},
+		Output:     []string{"mRNA1"},
+	}, {
+		Name:       "AttributeEqualsDecoded",
+		Predicates: []Predicate{AttributeEquals("Name", "Not,BRCA1", false)},
+		Output:     []string{"gene3"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			features, err := Filter(NewReader(strings.NewReader(input)), tt.Predicates...).ReadAll()
+			if err != io.EOF {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			var ids []string
+			for _, f := range features {
+				ids = append(ids, f.ID())
+			}
+			if !reflect.DeepEqual(ids, tt.Output) {
+				t.Errorf("Filter() error:\ngot \t%v\nwant \t%v", ids, tt.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gff
+
+import "strings"
+
+// GroupBy buckets features by the value of key, which is either one of the columns
+// "Seqid", "Source", "Type" or "Strand", or an attribute tag such as "Parent".
+// Columns take precedence over attributes with the same tag.
+//
+// Features with a multi-valued attribute, such as several Parents, are added to the bucket of each value.
+// Features lacking the key are grouped under "", unless omitMissing is set, in which case they are left out.
+func GroupBy(features []*Feature, key string, omitMissing bool) map[string][]*Feature {
+	groups := make(map[string][]*Feature)
+	for _, f := range features {
+		var vals []string
+		switch key {
+		case "Seqid":
+			vals = []string{f.Seqid}
+		case "Source":
+			vals = []string{f.Source}
+		case "Type":
+			vals = []string{f.Type}
+		case "Strand":
+			vals = []string{f.Strand}
+		default:
+			if val, ok := f.attribute(key); ok {
+				vals = strings.Split(val, ",")
+			}
+		}
+		if len(vals) == 0 {
+			if omitMissing {
+				continue
+			}
+			vals = []string{""}
+		}
+		for _, val := range vals {
+			groups[val] = append(groups[val], f)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,65 @@
+package gff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	gene := &Feature{Type: "gene", Attributes: map[string]string{"ID": "gene1"}}
+	mrna1 := &Feature{Type: "mRNA", Attributes: map[string]string{"ID": "mRNA1", "Parent": "gene1"}}
+	mrna2 := &Feature{Type: "mRNA", Attributes: map[string]string{"ID": "mRNA2", "Parent": "gene1"}}
+	exon := &Feature{Type: "exon", Attributes: map[string]string{"Parent": "mRNA1,mRNA2"}}
+	features := []*Feature{gene, mrna1, mrna2, exon}
+
+	tests := []struct {
+		Name        string
+		Key         string
+		OmitMissing bool
+		Output      map[string][]*Feature
+	}{{
+		Name: "Type",
+		Key:  "Type",
+		Output: map[string][]*Feature{
+			"gene": {gene},
+			"mRNA": {mrna1, mrna2},
+			"exon": {exon},
+		},
+	}, {
+		Name: "Parent",
+		Key:  "Parent",
+		Output: map[string][]*Feature{
+			"":      {gene},
+			"gene1": {mrna1, mrna2},
+			"mRNA1": {exon},
+			"mRNA2": {exon},
+		},
+	}, {
+		Name:   "MissingKey",
+		Key:    "Note",
+		Output: map[string][]*Feature{"": features},
+	}, {
+		Name:        "MissingKeyOmitted",
+		Key:         "Note",
+		OmitMissing: true,
+		Output:      map[string][]*Feature{},
+	}, {
+		Name:        "ParentOmitted",
+		Key:         "Parent",
+		OmitMissing: true,
+		Output: map[string][]*Feature{
+			"gene1": {mrna1, mrna2},
+			"mRNA1": {exon},
+			"mRNA2": {exon},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out := GroupBy(features, tt.Key, tt.OmitMissing)
+			if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("GroupBy() error: unexpected value\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
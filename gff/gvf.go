@@ -0,0 +1,53 @@
+// Accessors for the Genome Variation Format, a gff3 extension for sequence variants
+// http://www.sequenceontology.org/resources/gvf.html
+
+package gff
+
+import (
+	"errors"
+	"strings"
+)
+
+// GVFVersion is the version of the GVF spec that ToGVF follows, for a ##gvf-version pragma
+const GVFVersion = "1.10"
+
+// gvfAttributes are the attributes every GVF feature must have, in the order ToGVF writes them
+var gvfAttributes = []string{"ID", "Variant_seq", "Reference_seq"}
+
+// VariantSeq returns the decoded sequences listed in the Variant_seq attribute of a GVF feature,
+// or nil if it has none. A "-" is a deletion, "." a missing sequence and "~" a sequence that isn't given.
+func (f *Feature) VariantSeq() []string {
+	val, ok := f.attribute("Variant_seq")
+	if !ok || val == "" {
+		return nil
+	}
+	seqs := strings.Split(val, ",")
+	for i, seq := range seqs {
+		if decoded, err := unescape(seq); err == nil {
+			seqs[i] = decoded
+		}
+	}
+	return seqs
+}
+
+// ReferenceSeq returns the decoded Reference_seq attribute of a GVF feature, or "" if it has none.
+// A "-" marks an insertion, and "~" a reference sequence that isn't given.
+func (f *Feature) ReferenceSeq() string {
+	val, _ := f.attribute("Reference_seq")
+	if decoded, err := unescape(val); err == nil {
+		return decoded
+	}
+	return val
+}
+
+// ToGVF returns the feature line in GVF form, with the ID, Variant_seq and Reference_seq attributes
+// that GVF requires written first, followed by any others in sorted order. An error is returned if
+// any of the required attributes is missing.
+func (f *Feature) ToGVF() (string, error) {
+	for _, key := range gvfAttributes {
+		if val, ok := f.attribute(key); !ok || val == "" {
+			return "", errors.New("GVF feature is missing the " + key + " attribute")
+		}
+	}
+	return f.line(gvfAttributes, false), nil
+}
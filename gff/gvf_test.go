@@ -0,0 +1,63 @@
+package gff
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeature_GVF(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Input        string
+		VariantSeq   []string
+		ReferenceSeq string
+		Output       string
+		Error        error
+	}{{
+		Name:         "SNV",
+		Input:        "chr16\tsamtools\tSNV\t49291141\t49291141\t.\t+\t.\tZygosity=heterozygous;Variant_seq=A,G;ID=ID_1;Reference_seq=G",
+		VariantSeq:   []string{"A", "G"},
+		ReferenceSeq: "G",
+		Output:       "chr16\tsamtools\tSNV\t49291141\t49291141\t.\t+\t.\tID=ID_1;Variant_seq=A,G;Reference_seq=G;Zygosity=heterozygous",
+	}, {
+		Name:         "Deletion",
+		Input:        "chr16\tsamtools\tdeletion\t49291360\t49291360\t.\t+\t.\tID=ID_2;Reference_seq=C;Variant_seq=-",
+		VariantSeq:   []string{"-"},
+		ReferenceSeq: "C",
+		Output:       "chr16\tsamtools\tdeletion\t49291360\t49291360\t.\t+\t.\tID=ID_2;Variant_seq=-;Reference_seq=C",
+	}, {
+		Name:         "Parent",
+		Input:        "chr16\tsamtools\tSNV\t49291141\t49291141\t.\t+\t.\tParent=gene1;ID=ID_3;Reference_seq=G;Variant_seq=A;Alias=rs1",
+		VariantSeq:   []string{"A"},
+		ReferenceSeq: "G",
+		Output:       "chr16\tsamtools\tSNV\t49291141\t49291141\t.\t+\t.\tID=ID_3;Variant_seq=A;Reference_seq=G;Alias=rs1;Parent=gene1",
+	}, {
+		Name:  "NotGVF",
+		Input: "chr16\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1",
+		Error: errors.New("GVF feature is missing the Variant_seq attribute"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f, err := NewReader(strings.NewReader(tt.Input)).Read()
+			if f == nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			if got := f.VariantSeq(); !reflect.DeepEqual(got, tt.VariantSeq) {
+				t.Errorf("VariantSeq() error:\ngot \t%v\nwant \t%v", got, tt.VariantSeq)
+			}
+			if got := f.ReferenceSeq(); got != tt.ReferenceSeq {
+				t.Errorf("ReferenceSeq() error:\ngot \t%v\nwant \t%v", got, tt.ReferenceSeq)
+			}
+			got, err := f.ToGVF()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("ToGVF() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if got != tt.Output {
+				t.Errorf("ToGVF() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
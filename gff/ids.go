@@ -0,0 +1,71 @@
+package gff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DuplicateIDError describes a feature reusing the ID of an earlier feature of another type or on another
+// seqid. The spec only allows an ID to be shared by the parts of a discontinuous feature, such as the
+// CDS lines of a multi-exon CDS, which all have the same type and seqid.
+type DuplicateIDError struct {
+	ID      string
+	Feature *Feature
+	First   *Feature // the first feature read with the ID
+}
+
+func (e *DuplicateIDError) Error() string {
+	return fmt.Sprintf("ID %s of %s feature on %s is already used by %s feature on %s",
+		e.ID, e.Feature.Type, e.Feature.Seqid, e.First.Type, e.First.Seqid)
+}
+
+// FeaturesByID returns the features read so far with each ID, in the order read, if TrackIDs is set.
+// Discontinuous features have all of their parts under the one ID. The map belongs to the reader,
+// and changes as more features are read.
+func (gr *Reader) FeaturesByID() map[string][]*Feature {
+	return gr.byID
+}
+
+// DuplicateIDs returns a *DuplicateIDError for each feature read so far that reused an ID against
+// the spec, if TrackIDs is set
+func (gr *Reader) DuplicateIDs() []error {
+	return gr.duplicateIDs
+}
+
+// trackID records the feature under its ID, noting if it conflicts with the first feature with that ID
+func (gr *Reader) trackID(f *Feature) {
+	id := f.ID()
+	if id == "" {
+		return
+	}
+	if gr.byID == nil {
+		gr.byID = make(map[string][]*Feature)
+	}
+	if existing := gr.byID[id]; len(existing) > 0 {
+		if first := existing[0]; first.Type != f.Type || first.Seqid != f.Seqid {
+			gr.duplicateIDs = append(gr.duplicateIDs, &DuplicateIDError{ID: id, Feature: f, First: first})
+		}
+	}
+	gr.byID[id] = append(gr.byID[id], f)
+}
+
+// Parts groups features by ID, so that the parts of each discontinuous feature, such as the CDS lines
+// of a CDS split across exons, are together. The parts of each ID are sorted by start and end, whatever
+// their strand. Features without an ID are left out.
+func Parts(features []*Feature) map[string][]*Feature {
+	parts := make(map[string][]*Feature)
+	for _, f := range features {
+		if id := f.ID(); id != "" {
+			parts[id] = append(parts[id], f)
+		}
+	}
+	for _, p := range parts {
+		sort.SliceStable(p, func(i, j int) bool {
+			if p[i].Start != p[j].Start {
+				return p[i].Start < p[j].Start
+			}
+			return p[i].End < p[j].End
+		})
+	}
+	return parts
+}
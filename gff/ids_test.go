@@ -0,0 +1,66 @@
+package gff
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_FeaturesByID(t *testing.T) {
+	input := strings.Join([]string{
+		"ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA00001",
+		"ctg123\t.\tCDS\t1201\t1500\t.\t+\t0\tID=cds00001;Parent=mRNA00001",
+		"ctg123\t.\tCDS\t3000\t3902\t.\t+\t0\tID=cds00001;Parent=mRNA00001", // discontinuous feature
+		"ctg123\t.\texon\t1050\t1500\t.\t+\t.\tParent=mRNA00001",
+		"ctg123\t.\texon\t5000\t5500\t.\t+\t.\tID=mRNA00001;Parent=mRNA00001", // reused by another type
+		"",
+	}, "\n")
+
+	r := NewReader(strings.NewReader(input))
+	r.TrackIDs = true
+	if _, err := r.ReadAll(); err != io.EOF {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+
+	byID := r.FeaturesByID()
+	if len(byID) != 2 || len(byID["cds00001"]) != 2 || len(byID["mRNA00001"]) != 2 {
+		t.Errorf("FeaturesByID() error: unexpected features\ngot \t%v", byID)
+	}
+	if got := byID["cds00001"]; got[0].Start != 1201 || got[1].Start != 3000 {
+		t.Errorf("FeaturesByID() error: discontinuous feature out of order\ngot \t%v", got)
+	}
+
+	errs := r.DuplicateIDs()
+	if len(errs) != 1 {
+		t.Fatalf("DuplicateIDs() error: got %d errors, want 1: %v", len(errs), errs)
+	}
+	want := "ID mRNA00001 of exon feature on ctg123 is already used by mRNA feature on ctg123"
+	if dup, ok := errs[0].(*DuplicateIDError); !ok || dup.Feature.Start != 5000 || errs[0].Error() != want {
+		t.Errorf("DuplicateIDs() error:\ngot \t%v\nwant \t%v", errs[0], want)
+	}
+}
+
+func TestParts(t *testing.T) {
+	input := strings.Join([]string{
+		"ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA00001",
+		"ctg123\t.\tCDS\t5000\t5500\t.\t+\t1\tID=cds00001;Parent=mRNA00001",
+		"ctg123\t.\tCDS\t1201\t1500\t.\t+\t0\tID=cds00001;Parent=mRNA00001",
+		"ctg123\t.\tCDS\t3000\t3902\t.\t+\t0\tID=cds00001;Parent=mRNA00001",
+		"ctg123\t.\texon\t1050\t1500\t.\t+\t.\tParent=mRNA00001",
+		"",
+	}, "\n")
+	features, err := NewReader(strings.NewReader(input)).ReadAllFeatures()
+	if err != nil {
+		t.Fatalf("ReadAllFeatures() error: %v", err)
+	}
+
+	parts := Parts(features)
+	want := map[string][]*Feature{
+		"mRNA00001": {features[0]},
+		"cds00001":  {features[2], features[3], features[1]},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("Parts() error: unexpected parts\ngot \t%v\nwant \t%v", parts, want)
+	}
+}
@@ -0,0 +1,75 @@
+package gff
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LiftMap places a seqid onto another, such as a scaffold onto the pseudomolecule it was concatenated
+// into, at Offset bases from the start of NewSeqid
+type LiftMap = struct {
+	NewSeqid string
+	Offset   uint64
+}
+
+// LiftOver moves features from the seqids in mapping onto their new seqids, renaming Seqid and shifting
+// Start and End by the offset. A Target attribute whose ID is in mapping is lifted the same way.
+// Missing positions stay missing. Features on seqids absent from mapping are left unchanged, or dropped
+// if dropUnmapped is set, and the lifted features are returned.
+//
+// No feature is changed if any lifted coordinate would overflow.
+func LiftOver(features []*Feature, mapping map[string]LiftMap, dropUnmapped bool) ([]*Feature, error) {
+	for _, f := range features {
+		if lift, ok := mapping[f.Seqid]; ok && f.End > math.MaxUint64-lift.Offset {
+			return features, fmt.Errorf("feature %s:%d-%d overflows when lifted onto %s by %d",
+				f.Seqid, f.Start, f.End, lift.NewSeqid, lift.Offset)
+		}
+		if target, ok := f.Target(); ok {
+			if lift, ok := mapping[target.ID]; ok && target.End > math.MaxUint64-lift.Offset {
+				return features, fmt.Errorf("target %s:%d-%d overflows when lifted onto %s by %d",
+					target.ID, target.Start, target.End, lift.NewSeqid, lift.Offset)
+			}
+		}
+	}
+
+	lifted := features[:0:0]
+	for _, f := range features {
+		lift, ok := mapping[f.Seqid]
+		if !ok && dropUnmapped {
+			continue
+		}
+		liftTarget(f, mapping)
+		if ok {
+			f.Seqid = lift.NewSeqid
+			if f.Start != MissingPositionField {
+				f.Start += lift.Offset
+			}
+			if f.End != MissingPositionField {
+				f.End += lift.Offset
+			}
+		}
+		lifted = append(lifted, f)
+	}
+	return lifted, nil
+}
+
+// liftTarget moves the Target attribute of f onto its new seqid, if its ID is in mapping.
+// The ID is percent-encoded once, spaces included, as it is stored encoded in Attributes.
+func liftTarget(f *Feature, mapping map[string]LiftMap) {
+	target, ok := f.Target()
+	if !ok {
+		return
+	}
+	lift, ok := mapping[target.ID]
+	if !ok {
+		return
+	}
+	fields := []string{escape(lift.NewSeqid, " ;=&,", false),
+		strconv.FormatUint(target.Start+lift.Offset, 10), strconv.FormatUint(target.End+lift.Offset, 10)}
+	if target.Strand != "" {
+		fields = append(fields, target.Strand)
+	}
+	f.Attributes["Target"] = strings.Join(fields, " ")
+}
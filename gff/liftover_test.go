@@ -0,0 +1,92 @@
+package gff
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLiftOver(t *testing.T) {
+	input := "scaffold_1\tEVM\tgene\t100\t900\t.\t+\t.\tID=gene1\n" +
+		"scaffold_1\tblastn\tmatch\t120\t180\t.\t+\t.\tID=match1;Target=scaffold_2 1 61 +\n" +
+		"scaffold_2\tEVM\tgene\t10\t90\t.\t-\t.\tID=gene2\n" +
+		"scaffold_9\tEVM\tgene\t1\t50\t.\t+\t.\tID=gene3\n" +
+		"scaffold_9\tblastn\tmatch\t5\t20\t.\t+\t.\tID=match2;Target=scaffold_2 1 16 +\n"
+	mapping := map[string]LiftMap{
+		"scaffold_1": {NewSeqid: "chr1", Offset: 0},
+		"scaffold_2": {NewSeqid: "chr1", Offset: 1100},
+	}
+
+	tests := []struct {
+		Name         string
+		Mapping      map[string]LiftMap
+		DropUnmapped bool
+		Output       []string
+		Error        error
+	}{{
+		Name:    "KeepUnmapped",
+		Mapping: mapping,
+		Output: []string{
+			"chr1\tEVM\tgene\t100\t900\t.\t+\t.\tID=gene1",
+			"chr1\tblastn\tmatch\t120\t180\t.\t+\t.\tID=match1;Target=chr1 1101 1161 +",
+			"chr1\tEVM\tgene\t1110\t1190\t.\t-\t.\tID=gene2",
+			"scaffold_9\tEVM\tgene\t1\t50\t.\t+\t.\tID=gene3",
+			"scaffold_9\tblastn\tmatch\t5\t20\t.\t+\t.\tID=match2;Target=chr1 1101 1116 +",
+		},
+	}, {
+		Name:         "DropUnmapped",
+		Mapping:      mapping,
+		DropUnmapped: true,
+		Output: []string{
+			"chr1\tEVM\tgene\t100\t900\t.\t+\t.\tID=gene1",
+			"chr1\tblastn\tmatch\t120\t180\t.\t+\t.\tID=match1;Target=chr1 1101 1161 +",
+			"chr1\tEVM\tgene\t1110\t1190\t.\t-\t.\tID=gene2",
+		},
+	}, {
+		Name:    "Overflow",
+		Mapping: map[string]LiftMap{"scaffold_2": {NewSeqid: "chr1", Offset: math.MaxUint64 - 50}},
+		Output: []string{
+			"scaffold_1\tEVM\tgene\t100\t900\t.\t+\t.\tID=gene1",
+			"scaffold_1\tblastn\tmatch\t120\t180\t.\t+\t.\tID=match1;Target=scaffold_2 1 61 +",
+			"scaffold_2\tEVM\tgene\t10\t90\t.\t-\t.\tID=gene2",
+			"scaffold_9\tEVM\tgene\t1\t50\t.\t+\t.\tID=gene3",
+			"scaffold_9\tblastn\tmatch\t5\t20\t.\t+\t.\tID=match2;Target=scaffold_2 1 16 +",
+		},
+		Error: errors.New("target scaffold_2:1-61 overflows when lifted onto chr1 by 18446744073709551565"),
+	}, {
+		Name:    "EscapedSeqid",
+		Mapping: map[string]LiftMap{"scaffold_2": {NewSeqid: "chr 1", Offset: 100}},
+		Output: []string{
+			"scaffold_1\tEVM\tgene\t100\t900\t.\t+\t.\tID=gene1",
+			"scaffold_1\tblastn\tmatch\t120\t180\t.\t+\t.\tID=match1;Target=chr%201 101 161 +",
+			"chr%201\tEVM\tgene\t110\t190\t.\t-\t.\tID=gene2",
+			"scaffold_9\tEVM\tgene\t1\t50\t.\t+\t.\tID=gene3",
+			"scaffold_9\tblastn\tmatch\t5\t20\t.\t+\t.\tID=match2;Target=chr%201 101 116 +",
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			features, err := NewReader(strings.NewReader(input)).ReadAllFeatures()
+			if err != nil {
+				t.Fatalf("ReadAllFeatures() error: %v", err)
+			}
+			lifted, err := LiftOver(features, tt.Mapping, tt.DropUnmapped)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Fatalf("LiftOver() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			var out []string
+			for _, f := range lifted {
+				out = append(out, f.String())
+			}
+			if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("LiftOver() error: unexpected features\ngot \t%q\nwant \t%q", out, tt.Output)
+			}
+			if want := "scaffold_2 1 16 +"; tt.DropUnmapped && features[4].Attributes["Target"] != want {
+				t.Errorf("LiftOver() error: dropped feature changed\ngot \t%v\nwant \t%v", features[4].Attributes["Target"], want)
+			}
+		})
+	}
+}
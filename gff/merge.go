@@ -0,0 +1,82 @@
+package gff
+
+import "sort"
+
+// Merge collapses overlapping or book-ended features on the same seqid into single features spanning them,
+// like bedtools merge, such as to turn the exons of a gene into the regions they cover. Features touch when
+// one ends on the position before the next starts. If sameStrand is set, only features on the same strand
+// are merged.
+//
+// The merged features are new, and are returned sorted by seqid, start and end. Each keeps the source,
+// type and strand of the features merged into it if they all agree, and "." (or "region" for the type)
+// otherwise. Score and phase are dropped, and only attributes that have the same value in every feature
+// merged are kept, so that a shared Parent survives but differing IDs don't.
+// Features with a missing start or end are left out.
+func Merge(features []*Feature, sameStrand bool) []*Feature {
+	sorted := make([]*Feature, 0, len(features))
+	for _, f := range features {
+		if f.Start != MissingPositionField && f.End != MissingPositionField {
+			sorted = append(sorted, f)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case a.Seqid != b.Seqid:
+			return a.Seqid < b.Seqid
+		case sameStrand && a.Strand != b.Strand:
+			return a.Strand < b.Strand
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		}
+		return a.End < b.End
+	})
+
+	var merged []*Feature
+	var current *Feature
+	for _, f := range sorted {
+		if current != nil && current.Seqid == f.Seqid && (!sameStrand || current.Strand == f.Strand) && f.Start <= current.End+1 {
+			mergeInto(current, f)
+			continue
+		}
+		current = &Feature{Seqid: f.Seqid, Source: f.Source, Type: f.Type, Start: f.Start, End: f.End,
+			Score: MissingScoreField, Strand: f.Strand, Phase: PhaseNone, Attributes: make(map[string]string, len(f.Attributes))}
+		for key, val := range f.Attributes {
+			current.Attributes[key] = val
+		}
+		merged = append(merged, current)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		switch {
+		case a.Seqid != b.Seqid:
+			return a.Seqid < b.Seqid
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		}
+		return a.End < b.End
+	})
+	return merged
+}
+
+// mergeInto extends the merged feature m to cover f, as described by Merge
+func mergeInto(m, f *Feature) {
+	if f.End > m.End {
+		m.End = f.End
+	}
+	if m.Source != f.Source {
+		m.Source = "."
+	}
+	if m.Type != f.Type {
+		m.Type = "region"
+	}
+	if m.Strand != f.Strand {
+		m.Strand = "."
+	}
+	for key, val := range m.Attributes {
+		if other, ok := f.Attributes[key]; !ok || other != val {
+			delete(m.Attributes, key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	exon := func(seqid string, start, end uint64, strand, id string) *Feature {
+		return &Feature{Seqid: seqid, Source: "EVM", Type: "exon", Start: start, End: end, Score: 0.5, Strand: strand, Phase: PhaseNone,
+			Attributes: map[string]string{"ID": id, "Parent": "mRNA1"}}
+	}
+	merged := func(seqid string, start, end uint64, strand string, attributes map[string]string) *Feature {
+		return &Feature{Seqid: seqid, Source: "EVM", Type: "exon", Start: start, End: end, Score: MissingScoreField, Strand: strand,
+			Phase: PhaseNone, Attributes: attributes}
+	}
+
+	tests := []struct {
+		Name       string
+		Input      []*Feature
+		SameStrand bool
+		Output     []*Feature
+	}{{
+		Name:   "Overlapping",
+		Input:  []*Feature{exon("ctg1", 1300, 1500, "+", "e2"), exon("ctg1", 1050, 1400, "+", "e1")},
+		Output: []*Feature{merged("ctg1", 1050, 1500, "+", map[string]string{"Parent": "mRNA1"})},
+	}, {
+		Name:   "Contained",
+		Input:  []*Feature{exon("ctg1", 1000, 9000, "+", "e1"), exon("ctg1", 3000, 4000, "+", "e2"), exon("ctg1", 5000, 6000, "+", "e3")},
+		Output: []*Feature{merged("ctg1", 1000, 9000, "+", map[string]string{"Parent": "mRNA1"})},
+	}, {
+		Name:   "Adjacent",
+		Input:  []*Feature{exon("ctg1", 1000, 1999, "+", "e1"), exon("ctg1", 2000, 2500, "+", "e2")},
+		Output: []*Feature{merged("ctg1", 1000, 2500, "+", map[string]string{"Parent": "mRNA1"})},
+	}, {
+		Name:  "Disjoint",
+		Input: []*Feature{exon("ctg1", 1000, 1998, "+", "e1"), exon("ctg1", 2000, 2500, "+", "e2"), exon("ctg2", 1000, 2500, "+", "e3")},
+		Output: []*Feature{
+			merged("ctg1", 1000, 1998, "+", map[string]string{"ID": "e1", "Parent": "mRNA1"}),
+			merged("ctg1", 2000, 2500, "+", map[string]string{"ID": "e2", "Parent": "mRNA1"}),
+			merged("ctg2", 1000, 2500, "+", map[string]string{"ID": "e3", "Parent": "mRNA1"}),
+		},
+	}, {
+		Name:   "MixedStrands",
+		Input:  []*Feature{exon("ctg1", 1000, 2000, "+", "e1"), exon("ctg1", 1500, 2500, "-", "e2")},
+		Output: []*Feature{merged("ctg1", 1000, 2500, ".", map[string]string{"Parent": "mRNA1"})},
+	}, {
+		Name:       "SameStrand",
+		Input:      []*Feature{exon("ctg1", 1000, 2000, "+", "e1"), exon("ctg1", 1500, 2500, "-", "e2"), exon("ctg1", 2001, 3000, "+", "e3")},
+		SameStrand: true,
+		Output: []*Feature{
+			merged("ctg1", 1000, 3000, "+", map[string]string{"Parent": "mRNA1"}),
+			merged("ctg1", 1500, 2500, "-", map[string]string{"ID": "e2", "Parent": "mRNA1"}),
+		},
+	}, {
+		Name:  "MissingPosition",
+		Input: []*Feature{exon("ctg1", MissingPositionField, 2000, "+", "e1")},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := Merge(tt.Input, tt.SameStrand); !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("Merge() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
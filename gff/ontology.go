@@ -0,0 +1,164 @@
+package gff
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SOTable maps between Sequence Ontology term names, such as "mRNA", and accessions, such as "SO:0000234",
+// for checking that column 3 (type) holds an SO term as the gff3 spec requires.
+type SOTable struct {
+	byName      map[string]string
+	byAccession map[string]string
+}
+
+// NewSOTable returns an empty SOTable
+func NewSOTable() *SOTable {
+	return &SOTable{byName: make(map[string]string), byAccession: make(map[string]string)}
+}
+
+// defaultSOTerms are the SO terms most often found in gff3 files, by accession
+var defaultSOTerms = map[string]string{
+	"SO:0000001": "region",
+	"SO:0000039": "match_part",
+	"SO:0000104": "polypeptide",
+	"SO:0000147": "exon",
+	"SO:0000148": "supercontig",
+	"SO:0000149": "contig",
+	"SO:0000178": "operon",
+	"SO:0000185": "primary_transcript",
+	"SO:0000188": "intron",
+	"SO:0000204": "five_prime_UTR",
+	"SO:0000205": "three_prime_UTR",
+	"SO:0000234": "mRNA",
+	"SO:0000252": "rRNA",
+	"SO:0000253": "tRNA",
+	"SO:0000274": "snRNA",
+	"SO:0000275": "snoRNA",
+	"SO:0000276": "miRNA",
+	"SO:0000316": "CDS",
+	"SO:0000318": "start_codon",
+	"SO:0000319": "stop_codon",
+	"SO:0000336": "pseudogene",
+	"SO:0000340": "chromosome",
+	"SO:0000343": "match",
+	"SO:0000419": "mature_protein_region",
+	"SO:0000516": "pseudogenic_transcript",
+	"SO:0000655": "ncRNA",
+	"SO:0000657": "repeat_region",
+	"SO:0000673": "transcript",
+	"SO:0000689": "cDNA_match",
+	"SO:0000704": "gene",
+	"SO:0001263": "ncRNA_gene",
+	"SO:0001877": "lnc_RNA",
+}
+
+// DefaultSOTable returns a table of the SO terms most often used in gff3 files, such as gene, mRNA, exon and CDS.
+// Load the full ontology with ReadSOTableOBO to check less common types.
+func DefaultSOTable() *SOTable {
+	t := NewSOTable()
+	for accession, name := range defaultSOTerms {
+		t.Add(accession, name)
+	}
+	return t
+}
+
+// ReadSOTableOBO reads the [Term] stanzas of an OBO file, such as so.obo from the Sequence Ontology.
+// Obsolete terms are skipped.
+func ReadSOTableOBO(r io.Reader) (*SOTable, error) {
+	t := NewSOTable()
+	var accession, name string
+	inTerm, obsolete := false, false
+	flush := func() {
+		if inTerm && !obsolete && accession != "" && name != "" {
+			t.Add(accession, name)
+		}
+		accession, name, obsolete = "", "", false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, DefaultBufferSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			flush()
+			inTerm = line == "[Term]"
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !inTerm || !ok {
+			continue
+		}
+		switch val = strings.TrimSpace(val); key {
+		case "id":
+			accession = val
+		case "name":
+			name = val
+		case "is_obsolete":
+			obsolete = val == "true"
+		}
+	}
+	flush()
+	return t, scanner.Err()
+}
+
+// ReadSOTableCSV reads a table of accession,name rows, such as "SO:0000704,gene".
+// A header row, or any other row whose first column isn't an SO accession, is skipped.
+func ReadSOTableCSV(r io.Reader) (*SOTable, error) {
+	t := NewSOTable()
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return t, err
+		}
+		if len(record) < 2 || !strings.HasPrefix(record[0], "SO:") {
+			continue
+		}
+		if strings.TrimSpace(record[1]) == "" {
+			return t, fmt.Errorf("line %d: missing name for %s", line, record[0])
+		}
+		t.Add(strings.TrimSpace(record[0]), strings.TrimSpace(record[1]))
+	}
+}
+
+// Add adds a term to the table, replacing any term with the same accession or name
+func (t *SOTable) Add(accession, name string) {
+	t.byAccession[accession] = name
+	t.byName[name] = accession
+}
+
+// Accession returns the accession of the term name, and whether the table has it
+func (t *SOTable) Accession(name string) (string, bool) {
+	accession, ok := t.byName[name]
+	return accession, ok
+}
+
+// Name returns the name of the term with accession, and whether the table has it
+func (t *SOTable) Name(accession string) (string, bool) {
+	name, ok := t.byAccession[accession]
+	return name, ok
+}
+
+// Valid reports whether typ is the name or the accession of a term in the table
+func (t *SOTable) Valid(typ string) bool {
+	_, name := t.byName[typ]
+	_, accession := t.byAccession[typ]
+	return name || accession
+}
+
+// TypeAccession returns the SO accession of the feature's type, which may be given as a term
+// name or as an accession, and false if the type isn't in table
+func (f *Feature) TypeAccession(table *SOTable) (string, bool) {
+	if _, ok := table.Name(f.Type); ok {
+		return f.Type, true
+	}
+	return table.Accession(f.Type)
+}
@@ -0,0 +1,85 @@
+package gff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeature_TypeAccession(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Type   string
+		Output string
+		Ok     bool
+	}{{
+		Name:   "Name",
+		Type:   "mRNA",
+		Output: "SO:0000234",
+		Ok:     true,
+	}, {
+		Name:   "Accession",
+		Type:   "SO:0000704",
+		Output: "SO:0000704",
+		Ok:     true,
+	}, {
+		Name: "Unknown",
+		Type: "messenger_rna",
+	}}
+
+	table := DefaultSOTable()
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := &Feature{Type: tt.Type}
+			out, ok := f.TypeAccession(table)
+			if out != tt.Output || ok != tt.Ok {
+				t.Errorf("TypeAccession() error:\ngot \t%v %v\nwant \t%v %v", out, ok, tt.Output, tt.Ok)
+			}
+			if valid := table.Valid(tt.Type); valid != tt.Ok {
+				t.Errorf("Valid() error:\ngot \t%v\nwant \t%v", valid, tt.Ok)
+			}
+		})
+	}
+}
+
+func TestReadSOTable(t *testing.T) {
+	obo := `format-version: 1.2
+ontology: so
+
+[Term]
+id: SO:0000704
+name: gene
+def: "A region (or regions) that includes all of the sequence elements necessary to encode a functional transcript." []
+
+[Term]
+id: SO:0000234
+name: mRNA
+synonym: "messenger RNA" EXACT []
+
+[Term]
+id: SO:0000378
+name: obsolete_term
+is_obsolete: true
+
+[Typedef]
+id: part_of
+name: part_of
+`
+	csv := "accession,name\nSO:0000704,gene\nSO:0000234, mRNA\n"
+
+	want := NewSOTable()
+	want.Add("SO:0000704", "gene")
+	want.Add("SO:0000234", "mRNA")
+
+	fromOBO, err := ReadSOTableOBO(strings.NewReader(obo))
+	if err != nil || !reflect.DeepEqual(fromOBO, want) {
+		t.Errorf("ReadSOTableOBO() error:\ngot \t%v %v\nwant \t%v", fromOBO, err, want)
+	}
+	fromCSV, err := ReadSOTableCSV(strings.NewReader(csv))
+	if err != nil || !reflect.DeepEqual(fromCSV, want) {
+		t.Errorf("ReadSOTableCSV() error:\ngot \t%v %v\nwant \t%v", fromCSV, err, want)
+	}
+	if name, ok := fromOBO.Name("SO:0000234"); name != "mRNA" || !ok {
+		t.Errorf("Name() error:\ngot \t%v %v\nwant \t%v %v", name, ok, "mRNA", true)
+	}
+}
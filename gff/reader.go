@@ -6,29 +6,185 @@
 // one or more semicolon separated fields.
 //
 // Feature lines that start with a # are considered comments and ignored,
-// and pragma handling hasn't been implemented at this time
+// apart from the ##gff-version, ##sequence-region, ### and ##FASTA pragmas.
+// The FASTA section at the end of a file is skipped by Read, and returned by ReadLine.
 package gff
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"iter"
+	"math"
 	"strconv"
-	"unicode/utf8"
+	"strings"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+	"github.com/awilkey/bio-format-tools-go/util"
 )
 
+// ErrTruncated is returned by a Strict Reader when the last line of the input has no newline
+// and is missing fields, as happens when a file is cut off mid-record
+var ErrTruncated = errors.New("truncated final record")
+
+// errGroupEnd is returned internally by parseFeature when it reaches a "###" directive while reading groups
+var errGroupEnd = errors.New("end of feature group")
+
 type Reader struct {
-	buf        *bufio.Reader
-	LineNumber uint64
-	r          io.Reader
+	buf         *bufio.Reader
+	LineNumber  uint64
+	r           io.Reader
+	counter     *util.CountingReader
+	stopAtGroup bool
+
+	// ExtraColumns allows lines with more than nine columns, keeping the extras in Feature.Extra.
+	// By default such lines are rejected as having the wrong number of fields.
+	ExtraColumns bool
+
+	// CaseInsensitive renames attribute tags differing only in case from a gff3 reserved tag, such as
+	// "id" or "parent", to the reserved tag, so the typed accessors such as ID and Parents find them.
+	// The gff3 spec says tags are case sensitive, so this is off by default.
+	CaseInsensitive bool
+
+	// Strict returns ErrTruncated for a final line that is cut off, rather than
+	// the generic error for a line with the wrong number of fields
+	Strict bool
+
+	// MergeRepeatedTags joins the values of a tag repeated in column 9, such as "Dbxref=A;Dbxref=B",
+	// into one comma-separated value. By default only the last value of a repeated tag is kept.
+	MergeRepeatedTags bool
+
+	// InternStrings shares one copy of each distinct Seqid, Source and Type between the features
+	// read, which cuts allocations for large files with few distinct values in those columns.
+	InternStrings bool
+	interned      map[string]string
+
+	// MissingScoreAsNaN stores math.NaN() as the score of features with an undefined "." score,
+	// rather than the MissingScoreField sentinel, so that a missing score can't pass for a real one in
+	// arithmetic. Either value is written back as ".".
+	MissingScoreAsNaN bool
+
+	closer io.Closer
+
+	// the first error, other than io.EOF, that ended iteration with All
+	err error
+
+	// SequenceRegions holds the bounds given by ##sequence-region pragmas read so far, by seqid
+	SequenceRegions map[string]*SequenceRegion
+
+	// Version is the value of the ##gff-version pragma, if one has been read.
+	// Version 2 files have GTF-style attributes, such as `gene_id "X";`, which are parsed as such.
+	Version string
+
+	// JoinContinuationLines appends lines with fewer than seven tabs to the attributes of the feature before
+	// them, for files from exporters that wrap long attribute columns. This breaks the gff3 spec, so is off by default.
+	JoinContinuationLines bool
+
+	// TrackIDs keeps every feature read with an ID attribute, for FeaturesByID, and records a DuplicateIDError
+	// for each feature that reuses the ID of a feature of another type or seqid, for DuplicateIDs
+	TrackIDs     bool
+	byID         map[string][]*Feature
+	duplicateIDs []error
+
+	// seqidMapper renames the seqid of each feature and sequence-region read, if set by SetSeqidMapper
+	seqidMapper func(string) string
+
+	// inFasta is set once the FASTA section at the end of the file is reached
+	inFasta bool
+
+	// the names of the pragmas read so far, for Capabilities
+	pragmas map[string]bool
+
+	// a line read ahead of parseFeature while looking for continuation lines
+	pending       []byte
+	pendingErr    error
+	hasPending    bool
+	pendingOffset int64
 }
 
-// NewReader returns a Reader.
+// DefaultBufferSize is the read buffer size used by NewReader, larger than the bufio default of 4096 bytes
+const DefaultBufferSize = 64 * 1024
+
+// NewReader returns a Reader with a read buffer of DefaultBufferSize bytes.
 func NewReader(r io.Reader) *Reader {
-	buf := bufio.NewReader(r)
+	return NewReaderSize(r, DefaultBufferSize)
+}
+
+// NewReaderSize returns a Reader with a read buffer of at least bufSize bytes.
+// Lines longer than the buffer are still read whole, but in fewer, larger reads.
+func NewReaderSize(r io.Reader, bufSize int) *Reader {
+	counter := util.NewCountingReader(r)
+	buf := bufio.NewReaderSize(counter, bufSize)
+	fileio.SkipBOM(buf)
 	var LineNumber uint64
-	return &Reader{buf, LineNumber, r}
+	return &Reader{buf: buf, LineNumber: LineNumber, r: r, counter: counter, SequenceRegions: make(map[string]*SequenceRegion)}
+}
+
+// SetSeqidMapper sets a function to rename the seqid of every feature and ##sequence-region read from then on,
+// such as to give "Chr1" and "1" a canonical name when combining files from different sources. A nil mapper
+// leaves seqids as they are.
+func (gr *Reader) SetSeqidMapper(mapper func(string) string) {
+	gr.seqidMapper = mapper
+}
+
+// mapSeqid returns seqid renamed by the reader's seqid mapper, if it has one
+func (gr *Reader) mapSeqid(seqid string) string {
+	if gr.seqidMapper == nil {
+		return seqid
+	}
+	return gr.seqidMapper(seqid)
+}
+
+// Offset returns the byte offset in the input of the next line to be read
+func (gr *Reader) Offset() int64 {
+	if gr.hasPending {
+		return gr.pendingOffset
+	}
+	return gr.counter.Count() - int64(gr.buf.Buffered())
+}
+
+// Rewind seeks back to the start of the file, so that the features can be read again.
+// The underlying reader must implement io.Seeker.
+func (gr *Reader) Rewind() error {
+	seeker, ok := gr.r.(io.Seeker)
+	if !ok {
+		return errors.New("reader is not seekable")
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gr.counter.Reset(0)
+	gr.buf.Reset(gr.counter)
+	fileio.SkipBOM(gr.buf)
+	gr.LineNumber = 0
+	gr.pending, gr.pendingErr, gr.hasPending = nil, nil, false
+	gr.byID, gr.duplicateIDs = nil, nil
+	gr.err = nil
+	gr.inFasta = false
+	return nil
+}
+
+// OpenReader opens the gff3 file at path and returns a Reader for it,
+// transparently decompressing gzip and bgzf files. Close must be called to close the file.
+func OpenReader(path string) (*Reader, error) {
+	rc, err := fileio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gr := NewReader(rc)
+	gr.closer = rc
+	return gr, nil
+}
+
+// Close closes the file opened by OpenReader. It does nothing for readers from NewReader.
+func (gr *Reader) Close() error {
+	if gr.closer == nil {
+		return nil
+	}
+	return gr.closer.Close()
 }
 
 // Read returns a pointer to a Feature. Input is assumed to be a properly formed gff3
@@ -36,7 +192,44 @@ func (gr *Reader) Read() (*Feature, error) {
 	return gr.parseFeature()
 }
 
-// ReadAll returns a slice of pointers to Features from an input of one-or-more lines
+// All returns an iterator over the remaining features, for use with range. Iteration stops at the
+// end of the input or at the first error, which is then returned by Err, as with bufio.Scanner.
+func (gr *Reader) All() iter.Seq[*Feature] {
+	return gr.all(gr.parseFeature)
+}
+
+// AllContext returns an iterator over the remaining features like All, that also stops if ctx is
+// canceled, which is checked every 64 features. Err then returns ctx.Err().
+func (gr *Reader) AllContext(ctx context.Context) iter.Seq[*Feature] {
+	return gr.all(fileio.CheckContext(ctx, gr.parseFeature))
+}
+
+// all returns an iterator over the features returned by read, recording the error that ends it for Err
+func (gr *Reader) all(read func() (*Feature, error)) iter.Seq[*Feature] {
+	return func(yield func(*Feature) bool) {
+		for gr.err == nil {
+			f, err := read()
+			if err != nil && err != io.EOF {
+				gr.err = err
+			}
+			if f != nil && !yield(f) {
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first error, other than io.EOF, that ended iteration with All
+func (gr *Reader) Err() error {
+	return gr.err
+}
+
+// ReadAll returns a slice of pointers to Features from an input of one-or-more lines.
+// Reaching the end of the input is reported as io.EOF, so callers must treat io.EOF as success.
+// See ReadAllFeatures for a version that returns nil instead.
 func (gr *Reader) ReadAll() (features []*Feature, err error) {
 	for {
 		feature, err := gr.parseFeature()
@@ -53,85 +246,318 @@ func (gr *Reader) ReadAll() (features []*Feature, err error) {
 	}
 }
 
-//func (gr *Reader) validateFeature(fields [][]byte) error{
-// TODO: Add ReadAndValidate
-//	if field := strings.TrimSpace(string(fields[0])); field == "" || field == "." {
-//		return errors.New("column 1 (seqid) must be defined")
-//	}
-//
-//	if field := strings.TrimSpace(string(fields[1])); field == "" {
-//		return errors.New("column 2 (source) must not be empty")
-//	}
-//
-//	if field := strings.TrimSpace(string(fields[2])); field == "" || field == "." {
-//		return errors.New("column 3 (type) must be defined")
-//	}
-//
-//	if field := strings.TrimSpace(string(fields[2])); field == "" || field == "." {
-//		return errors.New("column 3 (type) must be defined")
-//	}
-//
-//	if field := strings.TrimSpace(string(fields[3])); field == "." || field == "" {
-//		return errors.New("column 4 (start) must be defined")
-//	}
-//
-//	start,_ := strconv.ParseUint(string(fields[3]), 10, 64)
-//	if start < 1 {
-//		return errors.New("column 4 (start) must be one-based")
-//	}
-//
-//	if  field := strings.TrimSpace(string(fields[4])); field == "." || field == "" {
-//		return errors.New("column 5 (end) must be defined")
-//	}
-//
-//	end,_ := strconv.ParseUint(string(fields[4]), 10, 64)
-//	if end < 1 {
-//		return errors.New("column 5 (end) must be one-based")
-//	}
-//
-//	if start > end {
-//		return errors.New("column 5 (end) must be greater than or equal to column 4 (start)")
-//	}
-//
-//	return nil
-//}
+// ReadGroup returns the features up to the next "###" directive, which marks that all
+// forward references have been resolved. The final group is returned along with io.EOF.
+// Empty groups, such as from repeated "###" directives, are skipped.
+func (gr *Reader) ReadGroup() (features []*Feature, err error) {
+	gr.stopAtGroup = true
+	defer func() { gr.stopAtGroup = false }()
+	for {
+		feature, err := gr.parseFeature()
+		if err == errGroupEnd {
+			if len(features) == 0 {
+				continue
+			}
+			return features, nil
+		}
+		if feature != nil {
+			features = append(features, feature)
+		}
+		if err != nil {
+			return features, err
+		}
+	}
+}
+
+// ReadAllFeatures returns a slice of pointers to Features like ReadAll, but returns a nil error
+// once the input has been read to the end, like io.ReadAll. Any non-nil error is a real
+// problem with the input, and the features read before it are returned along with it.
+func (gr *Reader) ReadAllFeatures() ([]*Feature, error) {
+	features, err := gr.ReadAll()
+	if err == io.EOF {
+		err = nil
+	}
+	return features, err
+}
+
+// ReadContext returns a pointer to a Feature, or ctx.Err() if ctx has been canceled
+func (gr *Reader) ReadContext(ctx context.Context) (*Feature, error) {
+	return fileio.ReadContext(ctx, gr.parseFeature)
+}
+
+// ReadAllContext returns a slice of pointers to Features like ReadAll, but stops early
+// and returns ctx.Err() if ctx is canceled, which is checked every 64 features.
+func (gr *Reader) ReadAllContext(ctx context.Context) ([]*Feature, error) {
+	return fileio.ReadAllContext(ctx, gr.parseFeature)
+}
+
+// intern returns b as a string, reusing a previous copy if InternStrings is set
+func (gr *Reader) intern(b []byte) string {
+	if !gr.InternStrings {
+		return string(b)
+	}
+	if s, ok := gr.interned[string(b)]; ok { // the compiler avoids allocating for this lookup
+		return s
+	}
+	if gr.interned == nil {
+		gr.interned = make(map[string]string)
+	}
+	s := string(b)
+	gr.interned[s] = s
+	return s
+}
+
+// decodeColumn decodes %XX escapes in the seqid, source or type column.
+// Columns with malformed escapes are left as they are.
+func decodeColumn(b []byte) []byte {
+	if bytes.IndexByte(b, '%') == -1 {
+		return b
+	}
+	decoded, err := unescape(string(b))
+	if err != nil {
+		return b
+	}
+	return []byte(decoded)
+}
+
+// setVersion records the version given by a ##gff-version pragma, which must be 2 or 3
+func (gr *Reader) setVersion(line []byte) error {
+	fields := bytes.Fields(line)
+	if len(fields) != 2 {
+		return errors.New("malformed gff-version pragma")
+	}
+	version := string(fields[1])
+	if major := strings.SplitN(version, ".", 2)[0]; major != "2" && major != "3" {
+		return fmt.Errorf("unsupported gff version %q", version)
+	}
+	gr.Version = version
+	return nil
+}
+
+// gtf reports whether the file declared itself as gff version 2, with GTF-style attributes
+func (gr *Reader) gtf() bool {
+	return strings.HasPrefix(gr.Version, "2")
+}
+
+// parseGTFAttributes parses a GTF-style column 9 of `tag value;` pairs into attributes,
+// removing the quotes around values
+func (gr *Reader) parseGTFAttributes(field []byte, attributes map[string]string) {
+	for _, attr := range bytes.Split(field, []byte{';'}) {
+		attr = bytes.TrimSpace(attr)
+		sep := bytes.IndexAny(attr, " \t")
+		if sep == -1 {
+			continue
+		}
+		key := string(attr[:sep])
+		val := string(bytes.Trim(bytes.TrimSpace(attr[sep+1:]), `"`))
+		if prev, ok := attributes[key]; ok && gr.MergeRepeatedTags {
+			val = prev + "," + val
+		}
+		attributes[key] = val
+	}
+}
+
+// splitAttribute splits a gff3 `tag=value` attribute, trimming whitespace around the tag and value,
+// as in hand-edited files written `ID = cds00001 ; Parent = mRNA00001`
+func splitAttribute(attr []byte) (key, val string, ok bool) {
+	att := bytes.Split(attr, []byte{'='})
+	if len(att) != 2 {
+		return "", "", false
+	}
+	return string(bytes.TrimSpace(att[0])), string(bytes.TrimSpace(att[1])), true
+}
+
+// cutOff reports whether the last column of a line looks cut off mid-field:
+// empty, an incomplete phase, or ending in an attribute tag with no value
+func cutOff(fields [][]byte) bool {
+	last := bytes.TrimRight(fields[len(fields)-1], "\r")
+	switch {
+	case len(last) == 0:
+		return true
+	case len(fields) == 8:
+		_, err := ParsePhase(string(last))
+		return err != nil
+	case len(fields) == 9 && string(last) != ".":
+		attrs := bytes.Split(last, []byte{';'})
+		tag := attrs[len(attrs)-1]
+		return len(bytes.TrimSpace(tag)) > 0 && bytes.IndexByte(tag, '=') == -1
+	}
+	return false
+}
+
+// readLine returns the line read ahead by joinContinuations if there is one, otherwise the next line of the input
+func (gr *Reader) readLine() ([]byte, error) {
+	if gr.hasPending {
+		line, err := gr.pending, gr.pendingErr
+		gr.pending, gr.pendingErr, gr.hasPending = nil, nil, false
+		return line, err
+	}
+	return util.ReadLine(gr.buf)
+}
+
+// joinContinuations appends the continuation lines following a feature line to its attributes.
+// A continuation that starts with a new tag is separated from the attributes before it by a ';',
+// unless one is already there; otherwise it is taken to continue a wrapped value, and appended as-is.
+// The first line that isn't a continuation, such as a feature line of eight or nine columns,
+// is kept for the next call to readLine.
+func (gr *Reader) joinContinuations(line []byte) ([]byte, error) {
+	for {
+		offset := gr.Offset()
+		next, err := util.ReadLine(gr.buf)
+		trimmed := bytes.TrimSpace(next)
+		if len(trimmed) == 0 || trimmed[0] == '#' || bytes.Count(next, []byte{'\t'}) >= 7 {
+			gr.pending, gr.pendingErr, gr.hasPending, gr.pendingOffset = next, err, true, offset
+			return line, nil
+		}
 
-func (gr *Reader) parseFeature() (*Feature, error) {
-	var line []byte
-	var readErr error
-	// Read next line(s), skipping comments
-	for readErr == nil {
 		gr.LineNumber++
-		line, readErr = gr.buf.ReadBytes('\n')
-		if firstRune, _ := utf8.DecodeRune(line); firstRune == '#' || bytes.TrimSpace(line) == nil {
-			line = nil
-			continue //skip comments/pragma for now
+		line = bytes.TrimRight(line, "\r\n")
+		first := trimmed
+		if i := bytes.IndexByte(first, ';'); i != -1 {
+			first = first[:i]
+		}
+		if !bytes.HasSuffix(line, []byte{';'}) && trimmed[0] != ';' && bytes.IndexByte(first, '=') != -1 {
+			line = append(line, ';')
 		}
+		line = append(line, trimmed...)
+		if err != nil {
+			return line, err
+		}
+		line = append(line, '\n')
+	}
+}
+
+// LineType classifies the lines of a gff3 file, as returned by ReadLine
+type LineType int
 
-		break
+const (
+	LineFeature LineType = iota
+	LineComment
+	LinePragma      // a ## directive, including ### and ##FASTA
+	LineFastaHeader // a >seqid line of the FASTA section
+	LineSequence    // any other line of the FASTA section
+	LineBlank
+)
+
+func (t LineType) String() string {
+	switch t {
+	case LineFeature:
+		return "Feature"
+	case LineComment:
+		return "Comment"
+	case LinePragma:
+		return "Pragma"
+	case LineFastaHeader:
+		return "FastaHeader"
+	case LineSequence:
+		return "Sequence"
+	case LineBlank:
+		return "Blank"
 	}
+	return fmt.Sprintf("LineType(%d)", int(t))
+}
 
-	// Return if read error
-	if readErr != nil {
-		if len(line) == 0 && readErr == io.EOF {
-			return nil, io.EOF //EOF is expected, don't bother with error
-		} else if len(line) > 0 && readErr != io.EOF {
-			return nil, readErr //return error
+// ReadLine reads the next line of the file, of any type, returning its type and the line without its ending,
+// such as for tools that rewrite a file while keeping its comments. Feature lines are also returned parsed,
+// and pragmas are applied to the reader as by Read. Lines after a ##FASTA pragma, or from the first line
+// starting with '>', are FASTA headers and sequence.
+//
+// As with Read, the last line of the input is returned along with io.EOF, and io.EOF alone
+// once there are no more lines.
+func (gr *Reader) ReadLine() (LineType, []byte, *Feature, error) {
+	gr.LineNumber++
+	line, readErr := gr.readLine()
+	if len(line) == 0 && readErr != nil {
+		return LineBlank, nil, nil, readErr
+	}
+	if readErr != nil && readErr != io.EOF {
+		return LineBlank, nil, nil, readErr
+	}
+
+	trimmed := bytes.TrimSpace(line)
+	switch {
+	case gr.inFasta && len(trimmed) > 0 && trimmed[0] == '>':
+		return LineFastaHeader, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
+	case gr.inFasta:
+		return LineSequence, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
+	case len(trimmed) == 0:
+		return LineBlank, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
+	case bytes.HasPrefix(line, []byte("##")):
+		if err := gr.applyPragma(line); err != nil {
+			return LinePragma, bytes.TrimSuffix(line, []byte{'\n'}), nil, err
 		}
+		return LinePragma, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
+	case line[0] == '#':
+		return LineComment, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
+	case line[0] == '>': // a FASTA section without a ##FASTA pragma
+		gr.inFasta = true
+		return LineFastaHeader, bytes.TrimSuffix(line, []byte{'\n'}), nil, readErr
 	}
 
+	if gr.JoinContinuationLines && readErr == nil {
+		line, readErr = gr.joinContinuations(line)
+	}
+	feat, err := gr.parseFields(line, readErr)
+	return LineFeature, bytes.TrimSuffix(line, []byte{'\n'}), feat, err
+}
+
+// applyPragma records the ## pragma line, and applies the ones the reader understands
+func (gr *Reader) applyPragma(line []byte) error {
+	if bytes.HasPrefix(line, []byte("###")) {
+		return nil
+	}
+	gr.recordPragma(line)
+	switch {
+	case bytes.HasPrefix(line, []byte("##gff-version")):
+		return gr.setVersion(line)
+	case bytes.HasPrefix(line, []byte("##sequence-region")):
+		if region, err := parseSequenceRegion(line); err == nil {
+			region.Seqid = gr.mapSeqid(region.Seqid)
+			gr.SequenceRegions[region.Seqid] = region
+		}
+	case bytes.HasPrefix(line, []byte("##FASTA")):
+		gr.inFasta = true
+	}
+	return nil
+}
+
+// parseFeature returns the next feature, skipping the other lines of the file
+func (gr *Reader) parseFeature() (*Feature, error) {
+	for {
+		typ, line, feat, err := gr.ReadLine()
+		if gr.stopAtGroup && typ == LinePragma && bytes.Equal(bytes.TrimSpace(line), []byte("###")) {
+			return nil, errGroupEnd
+		}
+		if typ == LineFeature || err != nil {
+			return feat, err
+		}
+	}
+}
+
+// parseFields parses a feature line, returned by readLine along with readErr
+func (gr *Reader) parseFields(line []byte, readErr error) (*Feature, error) {
+	// A final line without a newline may have been cut off mid-record
+	partial := gr.Strict && readErr == io.EOF
+
 	fields := bytes.Split(line, []byte{'\t'})
 
 	// Throw error if wrong number of fields
-	if !(len(fields) == 9 || len(fields) == 8) {
+	if !(len(fields) == 9 || len(fields) == 8 || (gr.ExtraColumns && len(fields) > 9)) {
+		if partial {
+			return nil, ErrTruncated
+		}
 		return nil, errors.New("wrong number of fields")
 	}
+	if partial && cutOff(fields) {
+		return nil, ErrTruncated
+	}
+	fields[len(fields)-1] = bytes.TrimRight(fields[len(fields)-1], "\r\n") // the last column ends the line
 
 	// process feature
 	var feat = new(Feature)
-	feat.Seqid = string(fields[0])
-	feat.Source = string(fields[1])
-	feat.Type = string(fields[2])
+	feat.Seqid = gr.mapSeqid(gr.intern(decodeColumn(fields[0])))
+	feat.Source = gr.intern(decodeColumn(fields[1]))
+	feat.Type = gr.intern(decodeColumn(fields[2]))
 
 	feat.Start, _ = strconv.ParseUint(string(fields[3]), 10, 64)
 
@@ -139,31 +565,46 @@ func (gr *Reader) parseFeature() (*Feature, error) {
 
 	if fld := string(fields[5]); fld != "." {
 		feat.Score, _ = strconv.ParseFloat(fld, 64)
+	} else if gr.MissingScoreAsNaN {
+		feat.Score = math.NaN()
 	} else {
 		feat.Score = MissingScoreField
 	}
 
 	feat.Strand = string(fields[6])
 
-	if fld, _ := strconv.ParseInt(string(fields[7]), 10, 8); string(fields[7]) != "." && fld >= 0 && fld < 3 {
-		feat.Phase = int8(fld)
-	} else {
-		feat.Phase = MissingPhaseField
-	}
+	feat.Phase, _ = ParsePhase(string(fields[7])) // invalid phases are treated as undefined
 
-	if len(fields) == 9 {
+	if len(fields) >= 9 {
 		attributes := map[string]string{}
-		if string(fields[8]) != "." {
+		if gr.gtf() && string(fields[8]) != "." {
+			gr.parseGTFAttributes(fields[8], attributes)
+		} else if string(fields[8]) != "." {
 			attrFields := bytes.Split(fields[8], []byte{';'})
 			for _, attr := range attrFields {
-				att := bytes.Split(attr, []byte{'='})
-				if len(att) == 2 {
-					attributes[string(bytes.TrimSpace(att[0]))] = string(bytes.TrimSpace(att[1])) //Clean leading and trailing whitespace
+				if key, val, ok := splitAttribute(attr); ok {
+					if prev, ok := attributes[key]; ok && gr.MergeRepeatedTags {
+						val = prev + "," + val
+					}
+					attributes[key] = val
 				}
 			}
 		}
+		if gr.CaseInsensitive {
+			canonicalTags(attributes)
+		}
 		feat.Attributes = attributes
 	}
 
+	if len(fields) > 9 {
+		feat.Extra = make([]string, len(fields)-9)
+		for i, fld := range fields[9:] {
+			feat.Extra[i] = string(bytes.TrimRight(fld, "\r\n"))
+		}
+	}
+
+	if gr.TrackIDs {
+		gr.trackID(feat)
+	}
 	return feat, readErr
 }
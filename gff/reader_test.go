@@ -1,12 +1,17 @@
 package gff
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"reflect"
 	"strings"
 	"testing"
+	"unsafe"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
 )
 
 func TestRead(t *testing.T) {
@@ -75,6 +80,21 @@ func TestRead(t *testing.T) {
 		Name:  "EmptyLine",
 		Input: "\n",
 		Error: io.EOF,
+	}, {
+		Name:  "ByteOrderMark",
+		Input: "\xEF\xBB\xBFScaffold_102\tEVM\tCDS\t6452\t6485\t1e20\t+\t2\tID=CDS705;Parent=mRNA906",
+		Output: Feature{
+			Seqid:      "Scaffold_102",
+			Source:     "EVM",
+			Type:       "CDS",
+			Start:      6452,
+			End:        6485,
+			Score:      1e+20,
+			Strand:     "+",
+			Phase:      2,
+			Attributes: map[string]string{"ID": "CDS705", "Parent": "mRNA906"},
+		},
+		Error: io.EOF,
 	}}
 
 	for _, tt := range tests {
@@ -342,3 +362,647 @@ Scaffold_102	EVM	CDS	6452	6485	.	+`,
 		})
 	}
 }
+
+// endlessReader yields the same line forever, canceling its context once limit lines have been served
+type endlessReader struct {
+	line   []byte
+	served int
+	limit  int
+	cancel context.CancelFunc
+}
+
+func (er *endlessReader) Read(p []byte) (int, error) {
+	er.served++
+	if er.served == er.limit {
+		er.cancel()
+	}
+	return copy(p, er.line), nil
+}
+
+func TestReadAllContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	er := &endlessReader{line: []byte("Scaffold_102\tEVM\tCDS\t6452\t6485\t.\t+\t2\tID=CDS705\n"), limit: 1000, cancel: cancel}
+	r := NewReader(er)
+	out, err := r.ReadAllContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("ReadAllContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+	if len(out) < 1000 || len(out) > 1000+fileio.ContextCheckRecords {
+		t.Errorf("ReadAllContext() error: did not stop promptly, read %d features", len(out))
+	}
+
+	if _, err := r.ReadContext(ctx); err != context.Canceled {
+		t.Errorf("ReadContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+}
+
+func TestReader_AllContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	er := &endlessReader{line: []byte("Scaffold_102\tEVM\tCDS\t6452\t6485\t.\t+\t2\tID=CDS705\n"), limit: 1000, cancel: cancel}
+	r := NewReader(er)
+	var n int
+	for range r.AllContext(ctx) {
+		n++
+	}
+	if err := r.Err(); err != context.Canceled {
+		t.Errorf("AllContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+	if n < 1000 || n > 1000+fileio.ContextCheckRecords {
+		t.Errorf("AllContext() error: did not stop promptly, read %d features", n)
+	}
+}
+
+func TestReadGroup(t *testing.T) {
+	input := `##gff-version 3
+ctg123	.	gene	1000	9000	.	+	.	ID=gene00001
+ctg123	.	mRNA	1050	9000	.	+	.	ID=mRNA00001;Parent=gene00001
+###
+###
+ctg123	.	gene	10000	19000	.	+	.	ID=gene00002
+###
+ctg123	.	gene	20000	29000	.	+	.	ID=gene00003
+ctg123	.	mRNA	20050	29000	.	+	.	ID=mRNA00003;Parent=gene00003`
+	expected := [][]string{{"gene00001", "mRNA00001"}, {"gene00002"}, {"gene00003", "mRNA00003"}}
+	errs := []error{nil, nil, io.EOF}
+
+	r := NewReader(strings.NewReader(input))
+	for i := range expected {
+		group, err := r.ReadGroup()
+		if !reflect.DeepEqual(err, errs[i]) {
+			t.Errorf("ReadGroup() error: unexpected error in group %d\ngot \t%v\nwant \t%v", i, err, errs[i])
+		}
+		var ids []string
+		for _, f := range group {
+			ids = append(ids, f.Attributes["ID"])
+		}
+		if !reflect.DeepEqual(ids, expected[i]) {
+			t.Errorf("ReadGroup() error: unexpected group %d\ngot \t%v\nwant \t%v", i, ids, expected[i])
+		}
+	}
+
+	if group, err := r.ReadGroup(); len(group) != 0 || err != io.EOF {
+		t.Errorf("ReadGroup() error: expected empty group at EOF\ngot \t%v, %v", group, err)
+	}
+}
+
+func TestRewind(t *testing.T) {
+	input := "\xEF\xBB\xBF##gff-version 3\nctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene00001\nctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA00001\n"
+	r := NewReader(strings.NewReader(input))
+	first, _ := r.ReadAll()
+	firstLine := r.LineNumber
+	if err := r.Rewind(); err != nil {
+		t.Fatalf("Rewind() error: %v", err)
+	}
+	second, _ := r.ReadAll()
+	if !reflect.DeepEqual(first, second) || len(second) != 2 {
+		t.Errorf("Rewind() error: unexpected features\ngot \t%v\nwant \t%v", second, first)
+	}
+	if r.LineNumber != firstLine {
+		t.Errorf("Rewind() error: unexpected LineNumber\ngot \t%v\nwant \t%v", r.LineNumber, firstLine)
+	}
+
+	r = NewReader(io.MultiReader(strings.NewReader(input)))
+	if err := r.Rewind(); err == nil {
+		t.Errorf("Rewind() error: expected error for unseekable reader")
+	}
+}
+
+func TestReadCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output map[string]string
+	}{{
+		Name:   "Lowercase",
+		Input:  "id=mRNA00001;parent=gene00001;name=EDEN.1",
+		Output: map[string]string{"ID": "mRNA00001", "Parent": "gene00001", "Name": "EDEN.1"},
+	}, {
+		Name:   "ExactPreferred",
+		Input:  "ID=exact;id=lower",
+		Output: map[string]string{"ID": "exact", "id": "lower"},
+	}, {
+		Name:   "Unreserved",
+		Input:  "ID=gene1;gene_name=EDEN",
+		Output: map[string]string{"ID": "gene1", "gene_name": "EDEN"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader("ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\t" + tt.Input))
+			r.CaseInsensitive = true
+			f, _ := r.Read()
+			if f == nil || !reflect.DeepEqual(f.Attributes, tt.Output) {
+				t.Errorf("Read() error: case-insensitive tags\ngot \t%v\nwant \t%v", f, tt.Output)
+			}
+		})
+	}
+}
+
+func TestReadMissingScoreAsNaN(t *testing.T) {
+	input := "ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n" +
+		"ctg123\t.\tgene\t1000\t9000\t0.5\t+\t.\tID=gene2\n"
+	r := NewReader(strings.NewReader(input))
+	r.MissingScoreAsNaN = true
+	features, err := r.ReadAllFeatures()
+	if err != nil {
+		t.Fatalf("ReadAllFeatures() error: %v", err)
+	}
+	if len(features) != 2 {
+		t.Fatalf("ReadAllFeatures() error: got %d features, want 2", len(features))
+	}
+	if !math.IsNaN(features[0].Score) || features[1].Score != 0.5 {
+		t.Errorf("Read() error: unexpected scores\ngot \t%v, %v\nwant \tNaN, 0.5", features[0].Score, features[1].Score)
+	}
+	if got := features[0].ScorePtr(); got != nil {
+		t.Errorf("ScorePtr() error: got %v, want nil", *got)
+	}
+
+	var out strings.Builder
+	w, err := NewWriter(&out)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+	w.WriteAll(features)
+	want := "##gff-version " + FormatVersion + "\n" + strings.Replace(input, "\t0.5\t", "\t5e-01\t", 1)
+	if out.String() != want {
+		t.Errorf("WriteAll() error: NaN score not written as \".\"\ngot \t%q\nwant \t%q", out.String(), want)
+	}
+	if got := canonicalString(features[0]); !strings.Contains(got, "\t9000\t.\t+") {
+		t.Errorf("canonicalString() error: NaN score not written as \".\"\ngot \t%q", got)
+	}
+}
+
+func TestReadEightColumns(t *testing.T) {
+	input := "ctg1\t.\tCDS\t1\t10\t.\t+\t2\n" +
+		"ctg1\t.\tCDS\t11\t20\t.\t+\t1\r\n" +
+		"ctg1\t.\tCDS\t21\t30\t.\t+\t0"
+	features, err := NewReader(strings.NewReader(input)).ReadAllFeatures()
+	if err != nil {
+		t.Fatalf("ReadAllFeatures() error: %v", err)
+	}
+	var phases []Phase
+	for _, f := range features {
+		phases = append(phases, f.Phase)
+	}
+	if want := []Phase{Phase2, Phase1, Phase0}; !reflect.DeepEqual(phases, want) {
+		t.Errorf("Read() error: unexpected phases\ngot \t%v\nwant \t%v", phases, want)
+	}
+}
+
+func TestReadExtraColumns(t *testing.T) {
+	input := "Scaffold_102\tEVM\tCDS\t6452\t6485\t1e+20\t+\t2\tID=CDS705;Parent=mRNA906\tAlice\tBob\n"
+	want := Feature{
+		Seqid:      "Scaffold_102",
+		Source:     "EVM",
+		Type:       "CDS",
+		Start:      6452,
+		End:        6485,
+		Score:      1e+20,
+		Strand:     "+",
+		Phase:      2,
+		Attributes: map[string]string{"ID": "CDS705", "Parent": "mRNA906"},
+		Extra:      []string{"Alice", "Bob"},
+	}
+
+	r := NewReader(strings.NewReader(input))
+	if _, err := r.Read(); !reflect.DeepEqual(err, errors.New("wrong number of fields")) {
+		t.Errorf("Read() error: extra columns accepted in strict mode\ngot \t%v", err)
+	}
+
+	r = NewReader(strings.NewReader(input))
+	r.ExtraColumns = true
+	f, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if !reflect.DeepEqual(*f, want) {
+		t.Errorf("Read() error: unexpected read\ngot \t%v\nwant \t%v", *f, want)
+	}
+	if got := f.String() + "\n"; got != input {
+		t.Errorf("String() error: extra columns not written\ngot \t%q\nwant \t%q", got, input)
+	}
+}
+
+func TestReadInternStrings(t *testing.T) {
+	input := "ctg1\tEVM\tgene\t1\t100\t.\t+\t.\tID=gene1\nctg1\tEVM\tgene\t200\t300\t.\t+\t.\tID=gene2\n"
+	r := NewReader(strings.NewReader(input))
+	r.InternStrings = true
+	features, err := r.ReadAll()
+	if err != io.EOF || len(features) != 2 {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if unsafe.StringData(features[0].Source) != unsafe.StringData(features[1].Source) ||
+		unsafe.StringData(features[0].Type) != unsafe.StringData(features[1].Type) {
+		t.Errorf("ReadAll() error: repeated columns not interned")
+	}
+}
+
+// benchmarkInput has many features but only a handful of distinct seqids, sources and types
+func benchmarkInput() string {
+	var b strings.Builder
+	types := []string{"gene", "mRNA", "exon", "CDS"}
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&b, "Chr%d\tEVM\t%s\t%d\t%d\t.\t+\t.\tID=f%d\n", i%5, types[i%4], i+1, i+100, i)
+	}
+	return b.String()
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	input := benchmarkInput()
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("InternStrings=%v", intern), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := NewReader(strings.NewReader(input))
+				r.InternStrings = intern
+				_, _ = r.ReadAll()
+			}
+		})
+	}
+}
+
+func TestReadAllFeatures(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output int
+		Error  error
+	}{{
+		Name:   "CleanEOF",
+		Input:  "ctg1\tEVM\tgene\t1\t100\t.\t+\t.\tID=gene1\nctg1\tEVM\tgene\t200\t300\t.\t+\t.\tID=gene2\n",
+		Output: 2,
+	}, {
+		Name:  "Empty",
+		Input: "##gff-version 3\n",
+	}, {
+		Name:   "BadLine",
+		Input:  "ctg1\tEVM\tgene\t1\t100\t.\t+\t.\tID=gene1\nctg1\tEVM\tgene\n",
+		Output: 1,
+		Error:  errors.New("wrong number of fields"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			features, err := NewReader(strings.NewReader(tt.Input)).ReadAllFeatures()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("ReadAllFeatures() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if len(features) != tt.Output {
+				t.Errorf("ReadAllFeatures() error: got %d features want %d", len(features), tt.Output)
+			}
+		})
+	}
+}
+
+func TestReadStrict(t *testing.T) {
+	line := "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Name=abc"
+	tests := []struct {
+		Name   string
+		Input  string
+		Strict bool
+		Error  error
+	}{{
+		Name:   "Complete",
+		Input:  line + "\n",
+		Strict: true,
+	}, {
+		Name:   "NoFinalNewline",
+		Input:  line,
+		Strict: true,
+		Error:  io.EOF,
+	}, {
+		Name:   "CutMidColumn",
+		Input:  "ctg1\tEVM\tgene\t10",
+		Strict: true,
+		Error:  ErrTruncated,
+	}, {
+		Name:   "CutAfterTab",
+		Input:  "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\t",
+		Strict: true,
+		Error:  ErrTruncated,
+	}, {
+		Name:   "CutMidAttribute",
+		Input:  "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Na",
+		Strict: true,
+		Error:  ErrTruncated,
+	}, {
+		Name:  "CutMidColumnNotStrict",
+		Input: "ctg1\tEVM\tgene\t10",
+		Error: errors.New("wrong number of fields"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			r.Strict = tt.Strict
+			_, err := r.Read()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Read() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func TestReadMergeRepeatedTags(t *testing.T) {
+	input := "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Dbxref=EMBL:AA816246;Dbxref=NCBI_gi:10727410\n"
+	tests := []struct {
+		Name   string
+		Merge  bool
+		Output string
+	}{{
+		Name:   "KeepLast",
+		Output: "NCBI_gi:10727410",
+	}, {
+		Name:   "Merge",
+		Merge:  true,
+		Output: "EMBL:AA816246,NCBI_gi:10727410",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(input))
+			r.MergeRepeatedTags = tt.Merge
+			f, err := r.Read()
+			if err != nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			if got := f.Attributes["Dbxref"]; got != tt.Output {
+				t.Errorf("Read() error: unexpected Dbxref\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestReader_Offset(t *testing.T) {
+	lines := []string{
+		"##gff-version 3\n",
+		"ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n",
+		"ctg1\tEVM\tmRNA\t1000\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1\n",
+	}
+	r := NewReaderSize(strings.NewReader(strings.Join(lines, "")), 16)
+	if r.Offset() != 0 {
+		t.Errorf("Offset() error: got %d want 0", r.Offset())
+	}
+	_, _ = r.Read()
+	if want := int64(len(lines[0]) + len(lines[1])); r.Offset() != want {
+		t.Errorf("Offset() error: got %d want %d", r.Offset(), want)
+	}
+}
+
+func TestReadVersion(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Input      string
+		Version    string
+		Attributes map[string]string
+		Error      error
+	}{{
+		Name:       "GFF3",
+		Input:      "##gff-version 3.1.26\nctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Name=abc\n",
+		Version:    "3.1.26",
+		Attributes: map[string]string{"ID": "gene1", "Name": "abc"},
+	}, {
+		Name:       "GTF",
+		Input:      "##gff-version 2\nctg1\tEVM\texon\t1000\t1500\t.\t+\t.\tgene_id \"gene1\"; transcript_id \"gene1.1\"; exon_number 1;\n",
+		Version:    "2",
+		Attributes: map[string]string{"gene_id": "gene1", "transcript_id": "gene1.1", "exon_number": "1"},
+	}, {
+		Name:       "GTF2.5",
+		Input:      "##gff-version 2.5\nctg1\tEVM\texon\t1000\t1500\t.\t+\t.\tgene_id \"gene 1\";transcript_id \"gene1.1\"\n",
+		Version:    "2.5",
+		Attributes: map[string]string{"gene_id": "gene 1", "transcript_id": "gene1.1"},
+	}, {
+		Name:  "Unsupported",
+		Input: "##gff-version 1\nctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n",
+		Error: errors.New("unsupported gff version \"1\""),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			f, err := r.Read()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Read() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if err == nil {
+				if r.Version != tt.Version {
+					t.Errorf("Read() error: unexpected version\ngot \t%v\nwant \t%v", r.Version, tt.Version)
+				}
+				if !reflect.DeepEqual(f.Attributes, tt.Attributes) {
+					t.Errorf("Read() error: unexpected attributes\ngot \t%v\nwant \t%v", f.Attributes, tt.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestReadLineEndings(t *testing.T) {
+	lines := []string{
+		"##gff-version 3",
+		"ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1",
+		"ctg1\tEVM\tmRNA\t1000\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1",
+		"ctg1\tEVM\texon\t1000\t1500\t.\t+\t.\tParent=mRNA1",
+	}
+	tests := []struct {
+		Name  string
+		Input string
+	}{{
+		Name:  "LF",
+		Input: strings.Join(lines, "\n") + "\n",
+	}, {
+		Name:  "CRLF",
+		Input: strings.Join(lines, "\r\n") + "\r\n",
+	}, {
+		Name:  "CR",
+		Input: strings.Join(lines, "\r") + "\r",
+	}, {
+		Name:  "Mixed",
+		Input: lines[0] + "\r" + lines[1] + "\r\n" + lines[2] + "\n" + lines[3],
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			features, err := NewReader(strings.NewReader(tt.Input)).ReadAllFeatures()
+			if err != nil {
+				t.Fatalf("ReadAllFeatures() error: %v", err)
+			}
+			if len(features) != 3 {
+				t.Fatalf("ReadAllFeatures() error: got %d features want 3", len(features))
+			}
+			if features[2].Attributes["Parent"] != "mRNA1" {
+				t.Errorf("ReadAllFeatures() error: line ending left in attributes %q", features[2].Attributes["Parent"])
+			}
+		})
+	}
+}
+
+func TestReadEscapedColumns(t *testing.T) {
+	input := "##sequence-region chr%201 1 1000\nchr%201\tmy%09tool\tgene%25\t1\t100\t.\t+\t.\tID=gene1\n"
+	r := NewReader(strings.NewReader(input))
+	f, err := r.Read()
+	if f == nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if f.Seqid != "chr 1" || f.Source != "my\ttool" || f.Type != "gene%" {
+		t.Errorf("Read() error: columns not decoded\ngot \t%q %q %q\nwant \t%q %q %q", f.Seqid, f.Source, f.Type, "chr 1", "my\ttool", "gene%")
+	}
+	if _, ok := r.SequenceRegions["chr 1"]; !ok {
+		t.Errorf("Read() error: sequence-region seqid not decoded\ngot \t%v", r.SequenceRegions)
+	}
+}
+
+func TestReadSpacedAttributes(t *testing.T) {
+	input := "ctg123\t.\tCDS\t1201\t1500\t.\t+\t0\t ID = CDS705 ; Parent = mRNA906 ;\n"
+	f, err := NewReader(strings.NewReader(input)).Read()
+	if f == nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	want := map[string]string{"ID": "CDS705", "Parent": "mRNA906"}
+	if !reflect.DeepEqual(f.Attributes, want) {
+		t.Errorf("Read() error: attributes not trimmed\ngot \t%q\nwant \t%q", f.Attributes, want)
+	}
+}
+
+func TestReader_SetSeqidMapper(t *testing.T) {
+	input := "##sequence-region Chr1 1 9000\n" +
+		"Chr1\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n" +
+		"chr2\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene2\n"
+	r := NewReader(strings.NewReader(input))
+	r.SetSeqidMapper(strings.ToLower)
+	features, err := r.ReadAll()
+	if err != io.EOF {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	var got []string
+	for _, f := range features {
+		got = append(got, f.Seqid)
+	}
+	if want := []string{"chr1", "chr2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAll() error: seqids not mapped\ngot \t%v\nwant \t%v", got, want)
+	}
+	if region, ok := r.SequenceRegions["chr1"]; !ok || region.Seqid != "chr1" {
+		t.Errorf("ReadAll() error: sequence-region not mapped\ngot \t%v", r.SequenceRegions)
+	}
+}
+
+func TestReader_All(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output []string
+		Error  error
+	}{{
+		Name:   "Valid",
+		Input:  "ctg1\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1\nctg1\t.\tgene\t12000\t19000\t.\t+\t.\tID=gene2",
+		Output: []string{"gene1", "gene2"},
+	}, {
+		Name:   "Malformed",
+		Input:  "ctg1\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1\nctg1\t.\tgene\n" + "ctg1\t.\tgene\t12000\t19000\t.\t+\t.\tID=gene2\n",
+		Output: []string{"gene1"},
+		Error:  errors.New("wrong number of fields"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			var got []string
+			for f := range r.All() {
+				got = append(got, f.ID())
+			}
+			if !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("All() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+			if err := r.Err(); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Err() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func TestReader_ReadLine(t *testing.T) {
+	input := "##gff-version 3\n" +
+		"# a comment\n" +
+		"\n" +
+		"ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n" +
+		"###\n" +
+		"##FASTA\n" +
+		">ctg123\n" +
+		"cttctgggcgtacccgattctcggagaacttgccgcaccattccgccttg\n" +
+		"tgttcattgctgcctgcatgttcattgtctacctcggctacgtgtggcta"
+
+	want := []struct {
+		Type LineType
+		Line string
+	}{
+		{LinePragma, "##gff-version 3"},
+		{LineComment, "# a comment"},
+		{LineBlank, ""},
+		{LineFeature, "ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene1"},
+		{LinePragma, "###"},
+		{LinePragma, "##FASTA"},
+		{LineFastaHeader, ">ctg123"},
+		{LineSequence, "cttctgggcgtacccgattctcggagaacttgccgcaccattccgccttg"},
+		{LineSequence, "tgttcattgctgcctgcatgttcattgtctacctcggctacgtgtggcta"},
+	}
+
+	r := NewReader(strings.NewReader(input))
+	for i, w := range want {
+		typ, line, f, err := r.ReadLine()
+		if err != nil && !(err == io.EOF && i == len(want)-1) {
+			t.Fatalf("ReadLine() error: line %d: %v", i+1, err)
+		}
+		if typ != w.Type || string(line) != w.Line {
+			t.Errorf("ReadLine() error: line %d\ngot \t%v %q\nwant \t%v %q", i+1, typ, line, w.Type, w.Line)
+		}
+		if (f != nil) != (typ == LineFeature) {
+			t.Errorf("ReadLine() error: line %d: unexpected feature %v", i+1, f)
+		}
+	}
+	if _, _, _, err := r.ReadLine(); err != io.EOF {
+		t.Errorf("ReadLine() error: expected io.EOF, got %v", err)
+	}
+
+	// Read skips everything but the feature, FASTA included
+	features, err := NewReader(strings.NewReader(input)).ReadAll()
+	if err != io.EOF || len(features) != 1 || features[0].ID() != "gene1" {
+		t.Errorf("ReadAll() error: unexpected features %v, %v", features, err)
+	}
+}
+
+func TestReadJoinContinuationLines(t *testing.T) {
+	input := "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1;Name=EDEN\n" +
+		"Note=protein kinase;Dbxref=EMBL:AA8\n" +
+		"16246\n" +
+		"# comment\n" +
+		"ctg1\tEVM\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1\n" +
+		";Name=EDEN.1\n" +
+		"ctg1\tEVM\tregion\t1\t20000\t.\t+\t." // eight columns
+
+	r := NewReader(strings.NewReader(input))
+	r.JoinContinuationLines = true
+	features, err := r.ReadAll()
+	if err != io.EOF {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	want := []map[string]string{
+		{"ID": "gene1", "Name": "EDEN", "Note": "protein kinase", "Dbxref": "EMBL:AA816246"},
+		{"ID": "mRNA1", "Parent": "gene1", "Name": "EDEN.1"},
+		nil,
+	}
+	if len(features) != len(want) {
+		t.Fatalf("ReadAll() error: unexpected count\ngot \t%d\nwant \t%d", len(features), len(want))
+	}
+	for i, f := range features {
+		if !reflect.DeepEqual(f.Attributes, want[i]) {
+			t.Errorf("ReadAll() error: feature %d\ngot \t%v\nwant \t%v", i, f.Attributes, want[i])
+		}
+	}
+	if r.LineNumber != 7 {
+		t.Errorf("ReadAll() error: unexpected LineNumber\ngot \t%d\nwant \t%d", r.LineNumber, 7)
+	}
+
+	// continuation lines are rejected unless joining is enabled
+	if _, err := NewReader(strings.NewReader(input)).ReadAll(); !reflect.DeepEqual(err, errors.New("wrong number of fields")) {
+		t.Errorf("ReadAll() error: unexpected error\ngot \t%v\nwant \t%v", err, "wrong number of fields")
+	}
+}
@@ -0,0 +1,71 @@
+package gff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SequenceRegion is the extent of a landmark, as declared by a ##sequence-region pragma
+type SequenceRegion struct {
+	Seqid string
+	Start uint64
+	End   uint64
+}
+
+// String returns the ##sequence-region pragma for the region
+func (r *SequenceRegion) String() string {
+	return fmt.Sprintf("##sequence-region %s %d %d", escapeSeqid(r.Seqid), r.Start, r.End)
+}
+
+// BoundsError describes a feature that falls outside of the sequence region of its seqid
+type BoundsError struct {
+	Feature *Feature
+	Region  *SequenceRegion
+}
+
+func (e *BoundsError) Error() string {
+	return fmt.Sprintf("feature %s:%d-%d is outside of sequence-region %s:%d-%d",
+		e.Feature.Seqid, e.Feature.Start, e.Feature.End, e.Region.Seqid, e.Region.Start, e.Region.End)
+}
+
+// parseSequenceRegion parses a "##sequence-region seqid start end" pragma
+func parseSequenceRegion(line []byte) (*SequenceRegion, error) {
+	fields := bytes.Fields(line)
+	if len(fields) != 4 || string(fields[0]) != "##sequence-region" {
+		return nil, errors.New("malformed sequence-region pragma")
+	}
+	var region SequenceRegion
+	var err error
+	region.Seqid = string(decodeColumn(fields[1]))
+	if region.Start, err = strconv.ParseUint(string(fields[2]), 10, 64); err != nil {
+		return nil, errors.New("malformed sequence-region pragma")
+	}
+	if region.End, err = strconv.ParseUint(string(fields[3]), 10, 64); err != nil || region.End < region.Start {
+		return nil, errors.New("malformed sequence-region pragma")
+	}
+	return &region, nil
+}
+
+// CheckBounds returns a *BoundsError for every feature whose start or end falls outside of
+// the sequence region of its seqid. Features on seqids without a region are not checked.
+func CheckBounds(features []*Feature, regions map[string]*SequenceRegion) []error {
+	var errs []error
+	for _, f := range features {
+		region, ok := regions[f.Seqid]
+		if !ok {
+			continue
+		}
+		if f.Start < region.Start || f.End > region.End {
+			errs = append(errs, &BoundsError{Feature: f, Region: region})
+		}
+	}
+	return errs
+}
+
+// InRegion reports whether the feature is on seqid and overlaps any part of start-end,
+// using inclusive one-based coordinates.
+func (f *Feature) InRegion(seqid string, start, end uint64) bool {
+	return f.Seqid == seqid && f.Start <= end && f.End >= start
+}
@@ -0,0 +1,97 @@
+package gff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckBounds(t *testing.T) {
+	input := `##gff-version 3
+##sequence-region ctg123 1 1497228
+##sequence-region ctg124 1 20000
+##sequence-region malformed 1
+ctg123	.	gene	1000	9000	.	+	.	ID=gene00001
+ctg123	.	gene	1497000	1497300	.	+	.	ID=gene00002
+ctg124	.	gene	19000	21000	.	+	.	ID=gene00003
+ctg125	.	gene	1	99999999	.	+	.	ID=gene00004`
+
+	r := NewReader(strings.NewReader(input))
+	features, _ := r.ReadAll()
+
+	wantRegions := map[string]*SequenceRegion{
+		"ctg123": {Seqid: "ctg123", Start: 1, End: 1497228},
+		"ctg124": {Seqid: "ctg124", Start: 1, End: 20000},
+	}
+	if !reflect.DeepEqual(r.SequenceRegions, wantRegions) {
+		t.Errorf("SequenceRegions error: unexpected regions\ngot \t%v\nwant \t%v", r.SequenceRegions, wantRegions)
+	}
+
+	errs := CheckBounds(features, r.SequenceRegions)
+	want := []string{
+		"feature ctg123:1497000-1497300 is outside of sequence-region ctg123:1-1497228",
+		"feature ctg124:19000-21000 is outside of sequence-region ctg124:1-20000",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+		if be, ok := err.(*BoundsError); !ok || be.Feature.Seqid != be.Region.Seqid {
+			t.Errorf("CheckBounds() error: unexpected error type %T", err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckBounds() error: unexpected errors\ngot \t%v\nwant \t%v", got, want)
+	}
+}
+
+func TestFeature_InRegion(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  Feature
+		Seqid  string
+		Output bool
+	}{{
+		Name:   "Inside",
+		Input:  Feature{Seqid: "chr3", Start: 1200, End: 1800},
+		Seqid:  "chr3",
+		Output: true,
+	}, {
+		Name:   "OverlapsStart",
+		Input:  Feature{Seqid: "chr3", Start: 500, End: 1000},
+		Seqid:  "chr3",
+		Output: true,
+	}, {
+		Name:   "OverlapsEnd",
+		Input:  Feature{Seqid: "chr3", Start: 2000, End: 2500},
+		Seqid:  "chr3",
+		Output: true,
+	}, {
+		Name:   "SpansRegion",
+		Input:  Feature{Seqid: "chr3", Start: 1, End: 5000},
+		Seqid:  "chr3",
+		Output: true,
+	}, {
+		Name:   "Before",
+		Input:  Feature{Seqid: "chr3", Start: 1, End: 999},
+		Seqid:  "chr3",
+		Output: false,
+	}, {
+		Name:   "After",
+		Input:  Feature{Seqid: "chr3", Start: 2001, End: 3000},
+		Seqid:  "chr3",
+		Output: false,
+	}, {
+		Name:   "OtherSeqid",
+		Input:  Feature{Seqid: "chr3", Start: 1200, End: 1800},
+		Seqid:  "chr4",
+		Output: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := tt.Input.InRegion(tt.Seqid, 1000, 2000); got != tt.Output {
+				t.Errorf("InRegion() error: got %v want %v", got, tt.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package gff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sequence returns the bases of the reference covered by the feature, as fetched by ref with the feature's
+// seqid and one-based inclusive start and end. If revcomp is set, the bases of a feature on the "-" strand
+// are reverse complemented, so that they read 5' to 3' along the feature, as when extracting a CDS.
+// The slice returned by ref is not modified.
+func (f *Feature) Sequence(ref func(seqid string, start, end uint64) ([]byte, error), revcomp bool) ([]byte, error) {
+	if f.Start == MissingPositionField || f.End == MissingPositionField {
+		return nil, errors.New("feature has no start or end")
+	}
+	if f.Start > f.End {
+		return nil, fmt.Errorf("feature start %d is after end %d", f.Start, f.End)
+	}
+	seq, err := ref(f.Seqid, f.Start, f.End)
+	if err != nil {
+		return nil, err
+	}
+	if want := f.End - f.Start + 1; uint64(len(seq)) != want {
+		return nil, fmt.Errorf("reference returned %d bases for %s:%d-%d, expected %d", len(seq), f.Seqid, f.Start, f.End, want)
+	}
+	if revcomp && f.Strand == "-" {
+		return reverseComplement(seq), nil
+	}
+	return seq, nil
+}
+
+// complements maps each IUPAC nucleotide code to its complement, keeping case
+var complements = func() [256]byte {
+	var c [256]byte
+	for i := range c {
+		c[i] = byte(i)
+	}
+	for _, pair := range []string{"AT", "CG", "RY", "KM", "BV", "DH", "SS", "WW", "NN"} {
+		a, b := pair[0], pair[1]
+		c[a], c[b] = b, a
+		c[a+'a'-'A'], c[b+'a'-'A'] = b+'a'-'A', a+'a'-'A'
+	}
+	c['U'], c['u'] = 'A', 'a'
+	return c
+}()
+
+// reverseComplement returns a new slice with the reverse complement of seq
+func reverseComplement(seq []byte) []byte {
+	rc := make([]byte, len(seq))
+	for i, base := range seq {
+		rc[len(seq)-1-i] = complements[base]
+	}
+	return rc
+}
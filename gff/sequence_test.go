@@ -0,0 +1,68 @@
+package gff
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFeature_Sequence(t *testing.T) {
+	reference := map[string]string{"ctg1": "AACCGGTTacgtNRYA"}
+	ref := func(seqid string, start, end uint64) ([]byte, error) {
+		seq, ok := reference[seqid]
+		if !ok || end > uint64(len(seq)) {
+			return nil, errors.New("not in reference")
+		}
+		return []byte(seq[start-1 : end]), nil
+	}
+
+	tests := []struct {
+		Name    string
+		Input   Feature
+		RevComp bool
+		Output  []byte
+		Error   error
+	}{{
+		Name:   "Plus",
+		Input:  Feature{Seqid: "ctg1", Start: 3, End: 6, Strand: "+"},
+		Output: []byte("CCGG"),
+	}, {
+		Name:    "Minus",
+		Input:   Feature{Seqid: "ctg1", Start: 1, End: 4, Strand: "-"},
+		RevComp: true,
+		Output:  []byte("GGTT"),
+	}, {
+		Name:    "MinusMixedCase",
+		Input:   Feature{Seqid: "ctg1", Start: 8, End: 16, Strand: "-"},
+		RevComp: true,
+		Output:  []byte("TRYNacgtA"),
+	}, {
+		Name:   "MinusWithoutRevComp",
+		Input:  Feature{Seqid: "ctg1", Start: 1, End: 4, Strand: "-"},
+		Output: []byte("AACC"),
+	}, {
+		Name:  "MissingStart",
+		Input: Feature{Seqid: "ctg1", Start: MissingPositionField, End: 4},
+		Error: errors.New("feature has no start or end"),
+	}, {
+		Name:  "Reversed",
+		Input: Feature{Seqid: "ctg1", Start: 5, End: 4},
+		Error: errors.New("feature start 5 is after end 4"),
+	}, {
+		Name:  "Reference",
+		Input: Feature{Seqid: "ctg2", Start: 1, End: 4},
+		Error: errors.New("not in reference"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := tt.Input.Sequence(ref, tt.RevComp)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Sequence() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("Sequence() error:\ngot \t%s\nwant \t%s", out, tt.Output)
+			}
+		})
+	}
+}
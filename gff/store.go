@@ -0,0 +1,203 @@
+package gff
+
+// FeatureStore is a mutable in-memory collection of features, kept sorted by seqid, start and end
+// so that overlap queries don't have to scan every feature. Inserts, removes and queries take
+// O(log n) time, plus the number of features returned, on average.
+//
+// Each seqid is held in a treap (a randomized balanced binary search tree) whose nodes also track
+// the largest end in their subtree, so that subtrees ending before a query are skipped.
+// Features must not have their Seqid, Start or End changed while in the store.
+type FeatureStore struct {
+	trees map[string]*storeNode
+	ids   map[string][]storeEntry
+	seq   uint64
+	rng   uint64
+	len   int
+}
+
+// storeKey orders the nodes of a treap. seq breaks ties between features with the same coordinates.
+type storeKey struct {
+	start, end, seq uint64
+}
+
+func (k storeKey) less(o storeKey) bool {
+	switch {
+	case k.start != o.start:
+		return k.start < o.start
+	case k.end != o.end:
+		return k.end < o.end
+	}
+	return k.seq < o.seq
+}
+
+// storeEntry locates a feature with an ID, for Remove
+type storeEntry struct {
+	seqid string
+	key   storeKey
+}
+
+type storeNode struct {
+	feature     *Feature
+	key         storeKey
+	priority    uint64
+	maxEnd      uint64
+	left, right *storeNode
+}
+
+// NewFeatureStore returns an empty FeatureStore
+func NewFeatureStore() *FeatureStore {
+	return &FeatureStore{
+		trees: make(map[string]*storeNode),
+		ids:   make(map[string][]storeEntry),
+		rng:   0x9E3779B97F4A7C15,
+	}
+}
+
+// Len returns the number of features in the store
+func (s *FeatureStore) Len() int {
+	return s.len
+}
+
+// Insert adds a feature to the store. Features with the same ID, such as the lines of a
+// discontinuous feature, are all kept, and removed together by Remove.
+func (s *FeatureStore) Insert(f *Feature) {
+	s.seq++
+	node := &storeNode{feature: f, key: storeKey{f.Start, f.End, s.seq}, priority: s.random(), maxEnd: f.End}
+	s.trees[f.Seqid] = insertNode(s.trees[f.Seqid], node)
+	if id := f.ID(); id != "" {
+		s.ids[id] = append(s.ids[id], storeEntry{f.Seqid, node.key})
+	}
+	s.len++
+}
+
+// Remove removes every feature with the ID attribute id, and reports whether there were any.
+// Features without an ID can't be removed.
+func (s *FeatureStore) Remove(id string) bool {
+	entries, ok := s.ids[id]
+	if !ok {
+		return false
+	}
+	for _, e := range entries {
+		root := deleteNode(s.trees[e.seqid], e.key)
+		if root == nil {
+			delete(s.trees, e.seqid)
+		} else {
+			s.trees[e.seqid] = root
+		}
+	}
+	delete(s.ids, id)
+	s.len -= len(entries)
+	return true
+}
+
+// Query returns the features on seqid that overlap any part of start-end, using inclusive one-based
+// coordinates as in Feature.InRegion. Features are returned sorted by start, then end, then insertion order.
+func (s *FeatureStore) Query(seqid string, start, end uint64) []*Feature {
+	var out []*Feature
+	queryNode(s.trees[seqid], start, end, &out)
+	return out
+}
+
+// random returns the next treap priority from a xorshift generator, so stores are reproducible
+func (s *FeatureStore) random() uint64 {
+	s.rng ^= s.rng << 13
+	s.rng ^= s.rng >> 7
+	s.rng ^= s.rng << 17
+	return s.rng
+}
+
+// update recomputes maxEnd from the node and its children
+func (n *storeNode) update() {
+	n.maxEnd = n.key.end
+	if n.left != nil && n.left.maxEnd > n.maxEnd {
+		n.maxEnd = n.left.maxEnd
+	}
+	if n.right != nil && n.right.maxEnd > n.maxEnd {
+		n.maxEnd = n.right.maxEnd
+	}
+}
+
+func rotateRight(n *storeNode) *storeNode {
+	l := n.left
+	n.left, l.right = l.right, n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *storeNode) *storeNode {
+	r := n.right
+	n.right, r.left = r.left, n
+	n.update()
+	r.update()
+	return r
+}
+
+// insertNode adds node to the treap rooted at root, rotating it up past lower priority nodes
+func insertNode(root, node *storeNode) *storeNode {
+	if root == nil {
+		return node
+	}
+	if node.key.less(root.key) {
+		root.left = insertNode(root.left, node)
+		if root.left.priority > root.priority {
+			return rotateRight(root)
+		}
+	} else {
+		root.right = insertNode(root.right, node)
+		if root.right.priority > root.priority {
+			return rotateLeft(root)
+		}
+	}
+	root.update()
+	return root
+}
+
+// deleteNode removes the node with key from the treap rooted at root, if there is one
+func deleteNode(root *storeNode, key storeKey) *storeNode {
+	switch {
+	case root == nil:
+		return nil
+	case key.less(root.key):
+		root.left = deleteNode(root.left, key)
+	case root.key.less(key):
+		root.right = deleteNode(root.right, key)
+	default:
+		return mergeNodes(root.left, root.right)
+	}
+	root.update()
+	return root
+}
+
+// mergeNodes joins two treaps, where every key in a is less than every key in b
+func mergeNodes(a, b *storeNode) *storeNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		a.right = mergeNodes(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = mergeNodes(a, b.left)
+		b.update()
+		return b
+	}
+}
+
+// queryNode appends the features overlapping start-end in the treap rooted at n, in key order
+func queryNode(n *storeNode, start, end uint64, out *[]*Feature) {
+	if n == nil || n.maxEnd < start {
+		return
+	}
+	queryNode(n.left, start, end, out)
+	if n.key.start > end {
+		return // everything to the right starts later still
+	}
+	if n.key.end >= start {
+		*out = append(*out, n.feature)
+	}
+	queryNode(n.right, start, end, out)
+}
@@ -0,0 +1,95 @@
+package gff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFeatureStore(t *testing.T) {
+	feature := func(id, seqid string, start, end uint64) *Feature {
+		return &Feature{Seqid: seqid, Start: start, End: end, Attributes: map[string]string{"ID": id}}
+	}
+	ids := func(features []*Feature) []string {
+		var out []string
+		for _, f := range features {
+			out = append(out, f.ID())
+		}
+		return out
+	}
+
+	s := NewFeatureStore()
+	s.Insert(feature("gene2", "ctg1", 5000, 9000))
+	s.Insert(feature("gene1", "ctg1", 1000, 2000))
+	s.Insert(feature("other", "ctg2", 1000, 2000))
+
+	if got, want := ids(s.Query("ctg1", 1500, 5000)), []string{"gene1", "gene2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+
+	s.Insert(feature("cds1", "ctg1", 1200, 1500))
+	s.Insert(feature("cds1", "ctg1", 1800, 2500)) // second line of a discontinuous feature
+	s.Insert(feature("long", "ctg1", 10, 20000))
+	if got, want := ids(s.Query("ctg1", 1400, 1900)), []string{"long", "gene1", "cds1", "cds1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+
+	if !s.Remove("cds1") || s.Remove("missing") {
+		t.Errorf("Remove() error: unexpected result")
+	}
+	if got, want := ids(s.Query("ctg1", 1400, 1900)), []string{"long", "gene1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() error: after Remove()\ngot \t%v\nwant \t%v", got, want)
+	}
+
+	s.Remove("long")
+	if got, want := ids(s.Query("ctg1", 2001, 4999)), []string(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() error: gap between features\ngot \t%v\nwant \t%v", got, want)
+	}
+	if got, want := ids(s.Query("ctg2", 1, 1000)), []string{"other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() error: other seqid\ngot \t%v\nwant \t%v", got, want)
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len() error:\ngot \t%d\nwant \t%d", s.Len(), 3)
+	}
+}
+
+func TestFeatureStore_Many(t *testing.T) {
+	// compare against a linear scan while inserting and removing many overlapping features
+	s := NewFeatureStore()
+	live := map[string]*Feature{}
+	for i := 0; i < 2000; i++ {
+		start := uint64(i*37%5000 + 1)
+		f := &Feature{Seqid: "ctg1", Start: start, End: start + uint64(i%300), Attributes: map[string]string{"ID": fmt.Sprint(i)}}
+		s.Insert(f)
+		live[f.ID()] = f
+		if i%3 == 0 {
+			id := fmt.Sprint(i / 2)
+			if _, ok := live[id]; ok != s.Remove(id) {
+				t.Fatalf("Remove() error: feature %s", id)
+			}
+			delete(live, id)
+		}
+	}
+
+	for _, q := range [][2]uint64{{1, 1}, {100, 400}, {2500, 2500}, {4900, 6000}} {
+		got := s.Query("ctg1", q[0], q[1])
+		want := 0
+		for _, f := range live {
+			if f.InRegion("ctg1", q[0], q[1]) {
+				want++
+			}
+		}
+		if len(got) != want {
+			t.Errorf("Query(%d, %d) error: unexpected count\ngot \t%d\nwant \t%d", q[0], q[1], len(got), want)
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i].Start < got[i-1].Start {
+				t.Errorf("Query(%d, %d) error: features not sorted", q[0], q[1])
+				break
+			}
+		}
+	}
+	if s.Len() != len(live) {
+		t.Errorf("Len() error:\ngot \t%d\nwant \t%d", s.Len(), len(live))
+	}
+}
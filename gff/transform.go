@@ -0,0 +1,42 @@
+package gff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Transform streams features from r to w, one at a time, passing each through fn.
+// fn returns the feature to write, which may be modified or replaced, or nil to drop it.
+// Comments and pragmas are not copied, and the output starts with a new gff header.
+//
+// Errors from reading or from fn stop the transform, and are returned wrapped with the line they occurred on.
+// Errors writing w are returned as they are.
+func Transform(r io.Reader, w io.Writer, fn func(*Feature) (*Feature, error)) error {
+	gr := NewReader(r)
+	bw := bufio.NewWriter(w)
+	gw, err := NewWriter(bw)
+	if err != nil {
+		return err
+	}
+	for {
+		f, readErr := gr.Read()
+		if f != nil {
+			out, err := fn(f)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", gr.LineNumber, err)
+			}
+			if out != nil {
+				if err := gw.writeFeature(out); err != nil {
+					return err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return bw.Flush()
+		}
+		if readErr != nil {
+			return fmt.Errorf("line %d: %w", gr.LineNumber, readErr)
+		}
+	}
+}
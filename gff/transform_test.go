@@ -0,0 +1,89 @@
+package gff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	input := "##gff-version 3\n" +
+		"ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n" +
+		"ctg1\tEVM\tmRNA\t1000\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1\n" +
+		"ctg1\tEVM\texon\t1000\t1500\t.\t+\t.\tParent=mRNA1\n"
+
+	renameAndDropExons := func(f *Feature) (*Feature, error) {
+		if f.Type == "exon" {
+			return nil, nil
+		}
+		f.Seqid = "chr1"
+		return f, nil
+	}
+	failOnExons := func(f *Feature) (*Feature, error) {
+		if f.Type == "exon" {
+			return nil, errors.New("exons not allowed")
+		}
+		return f, nil
+	}
+
+	tests := []struct {
+		Name   string
+		Input  string
+		Fn     func(*Feature) (*Feature, error)
+		Output string
+		Error  error
+	}{{
+		Name:  "RenameAndDrop",
+		Input: input,
+		Fn:    renameAndDropExons,
+		Output: "##gff-version 3.2.1\n" +
+			"chr1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n" +
+			"chr1\tEVM\tmRNA\t1000\t9000\t.\t+\t.\tID=mRNA1;Parent=gene1\n",
+	}, {
+		Name:  "FnError",
+		Input: input,
+		Fn:    failOnExons,
+		Error: fmt.Errorf("line 4: %w", errors.New("exons not allowed")),
+	}, {
+		Name:  "ReadError",
+		Input: "ctg1\tEVM\tgene\n",
+		Fn:    renameAndDropExons,
+		Error: fmt.Errorf("line 1: %w", errors.New("wrong number of fields")),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := Transform(strings.NewReader(tt.Input), &b, tt.Fn)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Transform() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if err == nil && b.String() != tt.Output {
+				t.Errorf("Transform() error:\ngot \n%v \nwant \n%v", b.String(), tt.Output)
+			}
+		})
+	}
+}
+
+// errWriter fails every write
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestTransform_Errors(t *testing.T) {
+	input := "ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\tID=gene1\n"
+	errStop := errors.New("stop")
+	stop := func(f *Feature) (*Feature, error) { return nil, errStop }
+	keep := func(f *Feature) (*Feature, error) { return f, nil }
+
+	if err := Transform(strings.NewReader(input), &bytes.Buffer{}, stop); !errors.Is(err, errStop) {
+		t.Errorf("Transform() error: fn error not wrapped\ngot \t%v\nwant \t%v", err, errStop)
+	}
+	if err := Transform(strings.NewReader(input), errWriter{}, keep); !reflect.DeepEqual(err, errors.New("disk full")) {
+		t.Errorf("Transform() error: unexpected error\ngot \t%v\nwant \t%v", err, "disk full")
+	}
+}
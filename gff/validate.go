@@ -0,0 +1,142 @@
+package gff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+	"github.com/awilkey/bio-format-tools-go/util"
+)
+
+// ValidationIssue is a problem found with a line of a gff3 file
+type ValidationIssue struct {
+	Line    uint64
+	Message string
+}
+
+func (v ValidationIssue) String() string {
+	return fmt.Sprintf("line %d: %s", v.Line, v.Message)
+}
+
+// ValidateStream reads all of r and returns every issue found, in line order, rather than stopping
+// at the first. Feature lines are checked for the number of columns, undefined seqid, source or type,
+// coordinates, score, strand, phase and malformed attributes. IDs are checked across lines: an ID may
+// only be repeated by the lines of a discontinuous feature, which share its seqid and type.
+// Checking stops at the FASTA section, from a ##FASTA pragma or the first line starting with '>'.
+// An error reading r is reported as an issue on the line being read.
+func ValidateStream(r io.Reader) []ValidationIssue {
+	var issues []ValidationIssue
+	type firstUse struct {
+		line        uint64
+		seqid, kind string
+	}
+	ids := make(map[string]firstUse)
+
+	buf := bufio.NewReader(r)
+	fileio.SkipBOM(buf)
+	var lineNumber uint64
+	for {
+		lineNumber++
+		line, err := util.ReadLine(buf)
+		if err != nil && err != io.EOF {
+			return append(issues, ValidationIssue{lineNumber, err.Error()})
+		}
+		line = bytes.TrimRight(line, "\n")
+		if bytes.HasPrefix(line, []byte("##FASTA")) || bytes.HasPrefix(line, []byte{'>'}) {
+			return issues
+		}
+		if len(line) > 0 && line[0] != '#' && len(bytes.TrimSpace(line)) > 0 {
+			fields := bytes.Split(line, []byte{'\t'})
+			for _, msg := range validateFeature(fields) {
+				issues = append(issues, ValidationIssue{lineNumber, msg})
+			}
+			if len(fields) == 9 {
+				id, seqid, kind := attributeID(fields[8]), string(fields[0]), string(fields[2])
+				if first, ok := ids[id]; ok && (first.seqid != seqid || first.kind != kind) {
+					issues = append(issues, ValidationIssue{lineNumber, fmt.Sprintf("ID %s already used on line %d", id, first.line)})
+				} else if !ok && id != "" {
+					ids[id] = firstUse{lineNumber, seqid, kind}
+				}
+			}
+		}
+		if err == io.EOF {
+			return issues
+		}
+	}
+}
+
+// validateFeature returns a message for each problem with the columns of a feature line
+func validateFeature(fields [][]byte) []string {
+	if len(fields) != 8 && len(fields) != 9 {
+		return []string{fmt.Sprintf("wrong number of fields: expected 8 or 9, have %d", len(fields))}
+	}
+	var issues []string
+
+	if field := strings.TrimSpace(string(fields[0])); field == "" || field == "." {
+		issues = append(issues, "column 1 (seqid) must be defined")
+	}
+	if field := strings.TrimSpace(string(fields[1])); field == "" {
+		issues = append(issues, "column 2 (source) must not be empty")
+	}
+	if field := strings.TrimSpace(string(fields[2])); field == "" || field == "." {
+		issues = append(issues, "column 3 (type) must be defined")
+	}
+
+	start, startErr := strconv.ParseUint(string(fields[3]), 10, 64)
+	if startErr != nil || start < 1 {
+		issues = append(issues, "column 4 (start) must be a one-based integer")
+	}
+	end, endErr := strconv.ParseUint(string(fields[4]), 10, 64)
+	if endErr != nil || end < 1 {
+		issues = append(issues, "column 5 (end) must be a one-based integer")
+	}
+	if startErr == nil && endErr == nil && start > end {
+		issues = append(issues, "column 5 (end) must be greater than or equal to column 4 (start)")
+	}
+
+	if field := string(fields[5]); field != "." {
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			issues = append(issues, "column 6 (score) must be a number or '.'")
+		}
+	}
+	switch string(fields[6]) {
+	case "+", "-", ".", "?":
+	default:
+		issues = append(issues, "column 7 (strand) must be one of '+', '-', '.' or '?'")
+	}
+	if phase, err := ParsePhase(string(fields[7])); err != nil {
+		issues = append(issues, "column 8 (phase) must be one of '0', '1', '2' or '.'")
+	} else if phase == PhaseNone && string(fields[2]) == "CDS" {
+		issues = append(issues, "column 8 (phase) is required for CDS features")
+	}
+
+	if len(fields) == 9 && string(fields[8]) != "." {
+		for _, attr := range bytes.Split(fields[8], []byte{';'}) {
+			if len(bytes.TrimSpace(attr)) == 0 {
+				continue // trailing or doubled separators
+			}
+			kv := bytes.SplitN(attr, []byte{'='}, 2)
+			if len(kv) != 2 || len(bytes.TrimSpace(kv[0])) == 0 {
+				issues = append(issues, fmt.Sprintf("malformed attribute %q: expected tag=value", attr))
+			} else if _, err := unescape(string(kv[1])); err != nil {
+				issues = append(issues, fmt.Sprintf("malformed attribute %q: invalid escape", attr))
+			}
+		}
+	}
+
+	return issues
+}
+
+// attributeID returns the ID tag from an undecoded attributes column, or "" if there isn't one
+func attributeID(column []byte) string {
+	for _, attr := range bytes.Split(column, []byte{';'}) {
+		if key, val, ok := splitAttribute(attr); ok && key == "ID" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package gff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateStream(t *testing.T) {
+	input := strings.Join([]string{
+		"##gff-version 3",
+		"ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene00001",
+		"ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=mRNA00001;Parent=gene00001",
+		"ctg123\t.\tCDS\t1201\t1500\t.\t+\t0\tID=cds00001;Parent=mRNA00001",
+		"ctg123\t.\tCDS\t3000\t3902\t.\t+\t0\tID=cds00001;Parent=mRNA00001", // discontinuous feature
+		"ctg123\t.\texon\t1300\t1500\t.\t+\t.",
+		"ctg123\t.\texon",
+		".\t.\texon\t5000\t4000\t.\t+\t.\tParent=mRNA00001",
+		"ctg123\t.\tCDS\tx\t500\thigh\t*\t.\tParent=mRNA00001;Note",
+		"ctg123\t.\tmRNA\t1050\t9000\t.\t+\t.\tID=gene00001;Note=bad%2",
+		"ctg123\t.\tgene\t1000\t9000\t.\t+\t.\t ID = mRNA00001 ; Note = spaced",
+		"",
+	}, "\n")
+
+	want := []ValidationIssue{
+		{7, "wrong number of fields: expected 8 or 9, have 3"},
+		{8, "column 1 (seqid) must be defined"},
+		{8, "column 5 (end) must be greater than or equal to column 4 (start)"},
+		{9, "column 4 (start) must be a one-based integer"},
+		{9, "column 6 (score) must be a number or '.'"},
+		{9, "column 7 (strand) must be one of '+', '-', '.' or '?'"},
+		{9, "column 8 (phase) is required for CDS features"},
+		{9, `malformed attribute "Note": expected tag=value`},
+		{10, `malformed attribute "Note=bad%2": invalid escape`},
+		{10, "ID gene00001 already used on line 2"},
+		{11, "ID mRNA00001 already used on line 3"},
+	}
+
+	if got := ValidateStream(strings.NewReader(input)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateStream() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+	if got := ValidateStream(strings.NewReader("ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene00001\n")); got != nil {
+		t.Errorf("ValidateStream() error: unexpected issues in a valid file\ngot \t%v", got)
+	}
+}
+
+func TestValidateStream_Fasta(t *testing.T) {
+	feature := "ctg123\t.\tgene\t1000\t9000\t.\t+\t.\tID=gene00001\n"
+	tests := []struct {
+		Name   string
+		Input  string
+		Output []ValidationIssue
+	}{{
+		Name:  "Pragma",
+		Input: feature + "ctg123\t.\texon\n##FASTA\n>ctg123\nACTGACTAGCTAGCATCAGC\n",
+		Output: []ValidationIssue{
+			{2, "wrong number of fields: expected 8 or 9, have 3"},
+		},
+	}, {
+		Name:  "HeaderOnly",
+		Input: feature + ">ctg123\nACTGACTAGCTAGCATCAGC\nTTGACTAGCATCAGCACTGA",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := ValidateStream(strings.NewReader(tt.Input)); !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("ValidateStream() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
@@ -3,27 +3,96 @@ package gff
 import (
 	"fmt"
 	"io"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
 )
 
 // Writer allows writing gff3 files
 type Writer struct {
 	io.Writer
+	closer io.Closer
+
+	// SequenceRegions has WriteAll emit a ##sequence-region pragma before the first feature of each seqid,
+	// spanning from the lowest start to the highest end of that seqid's features
+	SequenceRegions bool
+
+	// AttributeOrder lists tags to write after ID and Parent, which always come first, in the order given.
+	// Any other tags follow in sorted order.
+	AttributeOrder []string
 }
 
 // NewWriter returns a writer after appending gff header
 func NewWriter(w io.Writer) (*Writer, error) {
-	_, _ = fmt.Fprintf(w, "##gff-version 3.2.1\n")
-	return &Writer{w}, nil
+	_, _ = fmt.Fprintf(w, "##gff-version %s\n", FormatVersion)
+	return &Writer{Writer: w}, nil
+}
+
+// OpenWriter creates the file at path and returns a writer for it, after appending gff header.
+// Files ending in .gz are gzip compressed, and files ending in .bgz are bgzf compressed.
+// Close must be called to flush and close the file.
+func OpenWriter(path string) (*Writer, error) {
+	wc, err := fileio.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(wc)
+	if err != nil {
+		_ = wc.Close()
+		return nil, err
+	}
+	w.closer = wc
+	return w, nil
+}
+
+// Close flushes and closes the file created by OpenWriter. It does nothing for writers from NewWriter.
+func (w *Writer) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
 }
 
 // WriteFeature writes a single gff feature line
 func (w *Writer) WriteFeature(f *Feature) {
-	_, _ = fmt.Fprintln(w, f)
+	_ = w.writeFeature(f)
+}
+
+// writeFeature writes a single gff feature line, returning any error from the underlying writer
+func (w *Writer) writeFeature(f *Feature) error {
+	_, err := fmt.Fprintln(w, f.line(w.AttributeOrder, true))
+	return err
 }
 
-// WriteAll writes all features in a slice
+// WriteAll writes all features in a slice, along with sequence-region pragmas if SequenceRegions is set
 func (w *Writer) WriteAll(f []*Feature) {
+	var regions map[string]*SequenceRegion
+	if w.SequenceRegions {
+		regions = featureRegions(f)
+	}
 	for _, line := range f {
-		_, _ = fmt.Fprintln(w, line)
+		if region, ok := regions[line.Seqid]; ok {
+			_, _ = fmt.Fprintln(w, region)
+			delete(regions, line.Seqid) // only before the first feature of the seqid
+		}
+		_, _ = fmt.Fprintln(w, line.line(w.AttributeOrder, true))
+	}
+}
+
+// featureRegions returns the extent of the features on each seqid
+func featureRegions(features []*Feature) map[string]*SequenceRegion {
+	regions := make(map[string]*SequenceRegion)
+	for _, f := range features {
+		region, ok := regions[f.Seqid]
+		if !ok {
+			regions[f.Seqid] = &SequenceRegion{Seqid: f.Seqid, Start: f.Start, End: f.End}
+			continue
+		}
+		if f.Start < region.Start {
+			region.Start = f.Start
+		}
+		if f.End > region.End {
+			region.End = f.End
+		}
 	}
+	return regions
 }
@@ -2,7 +2,11 @@ package gff
 
 import (
 	"bytes"
+	"io"
 	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -136,3 +140,130 @@ func TestWriteAll(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteRoundTrip(t *testing.T) {
+	// Lines taken from the Ensembl GRCh38 and NCBI RefSeq gff3 releases
+	tests := []struct {
+		Name  string
+		Input string
+	}{{
+		Name:  "Ensembl",
+		Input: "1\tensembl_havana\tgene\t65419\t71585\t.\t+\t.\tID=gene:ENSG00000186092;Name=OR4F5;biotype=protein_coding;description=olfactory receptor family 4 subfamily F member 5 [Source:HGNC Symbol%3BAcc:HGNC:14825];gene_id=ENSG00000186092;logic_name=ensembl_havana_gene_homo_sapiens;version=7",
+	}, {
+		Name:  "RefSeq",
+		Input: "NC_000001.11\tBestRefSeq\tgene\t11874\t14409\t.\t+\t.\tID=gene-DDX11L1;Dbxref=GeneID:100287102,HGNC:HGNC:37102;Name=DDX11L1;description=DEAD/H-box helicase 11 like 1 (pseudogene);gbkey=Gene;gene=DDX11L1;gene_biotype=transcribed_pseudogene;pseudo=true",
+	}, {
+		Name:  "EscapedColumns",
+		Input: "chr%201\tmy%09tool\tgene\t1\t100\t.\t+\t.\tID=gene1",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f, err := NewReader(strings.NewReader(tt.Input)).Read()
+			if f == nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			w.WriteFeature(f)
+			want := "##gff-version 3.2.1\n" + tt.Input + "\n"
+			if got := b.String(); got != want {
+				t.Errorf("WriteFeature() error:\ngot \n%v want \n%v", got, want)
+			}
+		})
+	}
+}
+
+func TestOpenWriter(t *testing.T) {
+	features := []*Feature{{
+		Seqid: "Chr1", Source: "EVM", Type: "gene", Start: 100, End: 200,
+		Score: MissingScoreField, Strand: "+", Phase: PhaseNone,
+		Attributes: map[string]string{"ID": "gene1"},
+	}}
+
+	for _, file := range []string{"out.gff3", "out.gff3.gz", "out.gff3.bgz"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), file)
+			w, err := OpenWriter(path)
+			if err != nil {
+				t.Fatalf("OpenWriter() error: %v", err)
+			}
+			w.WriteAll(features)
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			r, err := OpenReader(path)
+			if err != nil {
+				t.Fatalf("OpenReader() error: %v", err)
+			}
+			defer r.Close()
+			got, err := r.ReadAll()
+			if err != io.EOF {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, features) {
+				t.Errorf("OpenReader() error:\ngot \t%v\nwant \t%v", got, features)
+			}
+		})
+	}
+}
+
+func TestWriteAll_SequenceRegions(t *testing.T) {
+	features := []*Feature{
+		{Seqid: "ctg1", Source: "EVM", Type: "gene", Start: 1000, End: 9000, Score: MissingScoreField, Strand: "+", Phase: PhaseNone},
+		{Seqid: "ctg1", Source: "EVM", Type: "gene", Start: 500, End: 2000, Score: MissingScoreField, Strand: "-", Phase: PhaseNone},
+		{Seqid: "ctg2", Source: "EVM", Type: "gene", Start: 10, End: 20, Score: MissingScoreField, Strand: "+", Phase: PhaseNone},
+	}
+	want := "##gff-version 3.2.1\n" +
+		"##sequence-region ctg1 500 9000\n" +
+		"ctg1\tEVM\tgene\t1000\t9000\t.\t+\t.\n" +
+		"ctg1\tEVM\tgene\t500\t2000\t.\t-\t.\n" +
+		"##sequence-region ctg2 10 20\n" +
+		"ctg2\tEVM\tgene\t10\t20\t.\t+\t.\n"
+
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.SequenceRegions = true
+	w.WriteAll(features)
+	if got := b.String(); got != want {
+		t.Errorf("WriteAll() error:\ngot \n%v \nwant \n%v", got, want)
+	}
+
+	r := NewReader(strings.NewReader(b.String()))
+	_, _ = r.ReadAll()
+	if errs := CheckBounds(features, r.SequenceRegions); len(errs) != 0 {
+		t.Errorf("WriteAll() error: features outside of written regions %v", errs)
+	}
+}
+
+func TestWriteFeature_AttributeOrder(t *testing.T) {
+	f := &Feature{Seqid: "ctg1", Source: "EVM", Type: "mRNA", Start: 1000, End: 9000, Score: MissingScoreField, Strand: "+", Phase: PhaseNone,
+		Attributes: map[string]string{"Alias": "m1", "Dbxref": "GO:0046703", "ID": "mRNA1", "Name": "EDEN.1", "Note": "x", "Parent": "gene1"}}
+
+	tests := []struct {
+		Name   string
+		Input  []string
+		Output string
+	}{{
+		Name:   "Default",
+		Output: "ID=mRNA1;Parent=gene1;Alias=m1;Dbxref=GO:0046703;Name=EDEN.1;Note=x",
+	}, {
+		Name:   "Priority",
+		Input:  []string{"Name", "Parent", "Missing", "Note"},
+		Output: "ID=mRNA1;Parent=gene1;Name=EDEN.1;Note=x;Alias=m1;Dbxref=GO:0046703",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			w.AttributeOrder = tt.Input
+			w.WriteFeature(f)
+			want := "##gff-version 3.2.1\nctg1\tEVM\tmRNA\t1000\t9000\t.\t+\t.\t" + tt.Output + "\n"
+			if got := b.String(); got != want {
+				t.Errorf("WriteFeature() error:\ngot \n%v want \n%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package fileio
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// bgzfBlockSize is the most uncompressed data written to a single bgzf block,
+// chosen by htslib so a block never compresses to more than 64KiB
+const bgzfBlockSize = 0xff00
+
+// bgzfEOF is the empty block that marks the end of a bgzf file
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 0x42, 0x43, 0x02, 0x00,
+	0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+// BGZFWriter compresses data into the blocked gzip format used by bgzip and tabix.
+// Each block is a complete gzip member, so the output can also be read by any gzip reader.
+type BGZFWriter struct {
+	w      io.Writer
+	buf    []byte
+	block  bytes.Buffer
+	fw     *flate.Writer
+	err    error
+	closed bool
+}
+
+// NewBGZFWriter returns a BGZFWriter writing compressed blocks to w
+func NewBGZFWriter(w io.Writer) *BGZFWriter {
+	fw, _ := flate.NewWriter(nil, flate.DefaultCompression)
+	return &BGZFWriter{w: w, buf: make([]byte, 0, bgzfBlockSize), fw: fw}
+}
+
+// Write buffers p, writing out a block each time bgzfBlockSize bytes are buffered
+func (bw *BGZFWriter) Write(p []byte) (int, error) {
+	if bw.closed {
+		return 0, errors.New("bgzf: write to closed writer")
+	}
+	n := 0
+	for len(p) > 0 && bw.err == nil {
+		c := copy(bw.buf[len(bw.buf):cap(bw.buf)], p)
+		bw.buf = bw.buf[:len(bw.buf)+c]
+		p = p[c:]
+		n += c
+		if len(bw.buf) == cap(bw.buf) {
+			bw.err = bw.writeBlock(bw.buf)
+			bw.buf = bw.buf[:0]
+		}
+	}
+	return n, bw.err
+}
+
+// Flush writes any buffered data as a block
+func (bw *BGZFWriter) Flush() error {
+	if bw.err == nil && len(bw.buf) > 0 {
+		bw.err = bw.writeBlock(bw.buf)
+		bw.buf = bw.buf[:0]
+	}
+	return bw.err
+}
+
+// Close flushes any buffered data and writes the bgzf end-of-file marker. It does not close the underlying writer.
+func (bw *BGZFWriter) Close() error {
+	if bw.closed {
+		return bw.err
+	}
+	bw.closed = true
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	_, bw.err = bw.w.Write(bgzfEOF)
+	return bw.err
+}
+
+// writeBlock compresses data into a single gzip member, with the BC extra subfield giving its size
+func (bw *BGZFWriter) writeBlock(data []byte) error {
+	bw.block.Reset()
+	bw.fw.Reset(&bw.block)
+	if _, err := bw.fw.Write(data); err != nil {
+		return err
+	}
+	if err := bw.fw.Close(); err != nil {
+		return err
+	}
+
+	header := [18]byte{0x1f, 0x8b, 0x08, 0x04, 0, 0, 0, 0, 0, 0xff, 0x06, 0x00, 'B', 'C', 0x02, 0x00}
+	blockSize := len(header) + bw.block.Len() + 8
+	if blockSize > 1<<16 {
+		return errors.New("bgzf: compressed block too large")
+	}
+	binary.LittleEndian.PutUint16(header[16:], uint16(blockSize-1))
+
+	var trailer [8]byte
+	binary.LittleEndian.PutUint32(trailer[0:], crc32.ChecksumIEEE(data))
+	binary.LittleEndian.PutUint32(trailer[4:], uint32(len(data)))
+
+	if _, err := bw.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := bw.w.Write(bw.block.Bytes()); err != nil {
+		return err
+	}
+	_, err := bw.w.Write(trailer[:])
+	return err
+}
@@ -0,0 +1,16 @@
+package fileio
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// utf8BOM is the byte-order mark some tools write at the start of utf-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// SkipBOM discards a leading utf-8 byte-order mark, if present
+func SkipBOM(buf *bufio.Reader) {
+	if lead, _ := buf.Peek(len(utf8BOM)); bytes.Equal(lead, utf8BOM) {
+		_, _ = buf.Discard(len(utf8BOM))
+	}
+}
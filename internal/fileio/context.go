@@ -0,0 +1,47 @@
+package fileio
+
+import "context"
+
+// ContextCheckRecords is how many records ReadAllContext and CheckContext read between checks for
+// cancellation. The readers' docs give it as a number, so keep them in step if it changes.
+const ContextCheckRecords = 64
+
+// ReadContext returns the next record from read, or ctx.Err() if ctx has been canceled
+func ReadContext[T any](ctx context.Context, read func() (*T, error)) (*T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return read()
+}
+
+// CheckContext returns a read function that returns the records from read, but returns ctx.Err()
+// instead once ctx is canceled. The context is checked before the first record and every
+// ContextCheckRecords records after it.
+func CheckContext[T any](ctx context.Context, read func() (*T, error)) func() (*T, error) {
+	var i int
+	return func() (*T, error) {
+		if i%ContextCheckRecords == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		i++
+		return read()
+	}
+}
+
+// ReadAllContext returns the records from read until it returns an error, along with that error,
+// but stops early and returns ctx.Err() if ctx is canceled. The context is checked every
+// ContextCheckRecords records.
+func ReadAllContext[T any](ctx context.Context, read func() (*T, error)) (records []*T, err error) {
+	read = CheckContext(ctx, read)
+	for {
+		record, err := read()
+		if record != nil {
+			records = append(records, record)
+		}
+		if err != nil {
+			return records, err
+		}
+	}
+}
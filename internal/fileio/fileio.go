@@ -0,0 +1,102 @@
+// Package fileio opens and creates files for the gff and vcf readers and writers,
+// handling gzip and bgzf compression based on file contents or extension.
+package fileio
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// gzipMagic starts every gzip (and so every bgzf) stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Compression formats reported by Compression
+const (
+	None = "none"
+	Gzip = "gzip"
+	BGZF = "bgzf"
+)
+
+// Open opens path for reading, transparently decompressing gzip and bgzf files.
+// Compression is detected from the file contents rather than its extension.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewReader(f)
+	if magic, _ := buf.Peek(len(gzipMagic)); string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		compression := Gzip
+		if extra := gz.Header.Extra; len(extra) >= 4 && extra[0] == 'B' && extra[1] == 'C' {
+			compression = BGZF
+		}
+		return &readCloser{Reader: gz, closers: []io.Closer{gz, f}, compression: compression}, nil
+	}
+	return &readCloser{Reader: buf, closers: []io.Closer{f}, compression: None}, nil
+}
+
+// Compression returns the compression detected by Open for a reader it returned, or None for any other reader
+func Compression(r io.Reader) string {
+	if rc, ok := r.(*readCloser); ok {
+		return rc.compression
+	}
+	return None
+}
+
+// Create creates path for writing, compressing with gzip if it ends in .gz, or with bgzf if it ends in .bgz.
+// Closing the returned writer flushes any compressor before closing the file.
+func Create(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz := gzip.NewWriter(f)
+		return &writeCloser{Writer: gz, closers: []io.Closer{gz, f}}, nil
+	case strings.HasSuffix(path, ".bgz"):
+		bgz := NewBGZFWriter(f)
+		return &writeCloser{Writer: bgz, closers: []io.Closer{bgz, f}}, nil
+	}
+	return f, nil
+}
+
+// readCloser closes a chain of readers, decompressor first
+type readCloser struct {
+	io.Reader
+	closers     []io.Closer
+	compression string
+}
+
+func (rc *readCloser) Close() error {
+	return closeAll(rc.closers)
+}
+
+// writeCloser closes a chain of writers, outermost first so compressors are flushed
+type writeCloser struct {
+	io.Writer
+	closers []io.Closer
+}
+
+func (wc *writeCloser) Close() error {
+	return closeAll(wc.closers)
+}
+
+// closeAll closes every closer, returning the first error
+func closeAll(closers []io.Closer) error {
+	var first error
+	for _, c := range closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,155 @@
+package fileio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateOpen(t *testing.T) {
+	tests := []struct {
+		Name        string
+		File        string
+		Magic       []byte
+		Compression string
+	}{{
+		Name:        "Plain",
+		File:        "out.txt",
+		Compression: None,
+	}, {
+		Name:        "Gzip",
+		File:        "out.txt.gz",
+		Magic:       gzipMagic,
+		Compression: Gzip,
+	}, {
+		Name:        "Bgzf",
+		File:        "out.txt.bgz",
+		Magic:       gzipMagic,
+		Compression: BGZF,
+	}}
+
+	input := bytes.Repeat([]byte("chr1\t100\t.\tA\tC\n"), bgzfBlockSize/8) // spans several bgzf blocks
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.File)
+			w, err := Create(path)
+			if err != nil {
+				t.Fatalf("Create() error: %v", err)
+			}
+			if _, err := w.Write(input); err != nil {
+				t.Fatalf("Write() error: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			raw, _ := os.ReadFile(path)
+			if tt.Magic != nil && !bytes.HasPrefix(raw, tt.Magic) {
+				t.Errorf("Create() error: file is not compressed")
+			}
+			if tt.Magic == nil && !bytes.Equal(raw, input) {
+				t.Errorf("Create() error: plain file does not match input")
+			}
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() error: %v", err)
+			}
+			if got := Compression(r); got != tt.Compression {
+				t.Errorf("Compression() error:\ngot \t%v\nwant \t%v", got, tt.Compression)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+			if !bytes.Equal(got, input) {
+				t.Errorf("Open() error: read %d bytes, want %d", len(got), len(input))
+			}
+		})
+	}
+}
+
+func TestBGZFWriter_Close(t *testing.T) {
+	var b bytes.Buffer
+	w := NewBGZFWriter(&b)
+	_, _ = w.Write([]byte("data\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if !bytes.HasSuffix(b.Bytes(), bgzfEOF) {
+		t.Errorf("Close() error: missing bgzf EOF marker")
+	}
+}
+
+func TestCreate_BGZFEOF(t *testing.T) {
+	// the empty block from the SAM spec that marks the end of a bgzf file, spelled out
+	// rather than using bgzfEOF, so that a change to it is caught
+	canonicalEOF := []byte("\x1f\x8b\x08\x04\x00\x00\x00\x00\x00\xff\x06\x00\x42\x43\x02\x00" +
+		"\x1b\x00\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+	for _, input := range []string{"", "##fileformat=VCFv4.2\n", strings.Repeat("chr1\t100\t.\tA\tC\n", bgzfBlockSize/4)} {
+		t.Run(fmt.Sprintf("%d bytes", len(input)), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.vcf.bgz")
+			w, err := Create(path)
+			if err != nil {
+				t.Fatalf("Create() error: %v", err)
+			}
+			_, _ = io.WriteString(w, input)
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			raw, _ := os.ReadFile(path)
+			if !bytes.HasSuffix(raw, canonicalEOF) {
+				t.Errorf("Close() error: file does not end with the bgzf EOF block\ngot \t% x", raw[len(raw)-len(canonicalEOF):])
+			}
+			gz, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error: %v", err)
+			}
+			got, err := io.ReadAll(gz)
+			if err != nil {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			if string(got) != input {
+				t.Errorf("gzip.Reader error: read %d bytes, want %d", len(got), len(input))
+			}
+		})
+	}
+}
+
+func TestOpen_Multistream(t *testing.T) {
+	// two gzip members concatenated, as from `cat a.gz b.gz`, each ending mid-file
+	var b bytes.Buffer
+	for _, member := range []string{"chr1\t100\t.\tA\tC\n", "chr1\t200\t.\tG\tT\n"} {
+		gz := gzip.NewWriter(&b)
+		_, _ = gz.Write([]byte(member))
+		_ = gz.Close()
+	}
+	path := filepath.Join(t.TempDir(), "multi.gz")
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if want := "chr1\t100\t.\tA\tC\nchr1\t200\t.\tG\tT\n"; string(got) != want {
+		t.Errorf("Open() error: not every member read\ngot \t%q\nwant \t%q", got, want)
+	}
+}
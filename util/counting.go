@@ -0,0 +1,35 @@
+// Package util holds small helpers shared by the gff and vcf packages.
+package util
+
+import "io"
+
+// CountingReader wraps an io.Reader, counting the bytes read through it.
+//
+// When a CountingReader sits under a bufio.Reader, Count includes bytes that have been
+// buffered but not yet returned by the bufio.Reader. Subtract its Buffered() to get the
+// offset of the next byte the caller will see.
+type CountingReader struct {
+	r io.Reader
+	n int64
+}
+
+// NewCountingReader returns a CountingReader reading from r, with a count of zero
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+func (cr *CountingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// Count returns the number of bytes read from the underlying reader
+func (cr *CountingReader) Count() int64 {
+	return cr.n
+}
+
+// Reset sets the count to n, such as after seeking the underlying reader to offset n
+func (cr *CountingReader) Reset(n int64) {
+	cr.n = n
+}
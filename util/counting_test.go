@@ -0,0 +1,43 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCountingReader(t *testing.T) {
+	input := strings.Repeat("0123456789", 10)
+
+	cr := NewCountingReader(strings.NewReader(input))
+	p := make([]byte, 25)
+	if _, err := io.ReadFull(cr, p); err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if cr.Count() != 25 {
+		t.Errorf("Count() error: got %d want 25", cr.Count())
+	}
+	_, _ = io.ReadAll(cr)
+	if cr.Count() != int64(len(input)) {
+		t.Errorf("Count() error: got %d want %d", cr.Count(), len(input))
+	}
+	cr.Reset(0)
+	if cr.Count() != 0 {
+		t.Errorf("Reset() error: got %d want 0", cr.Count())
+	}
+}
+
+func TestCountingReader_Buffered(t *testing.T) {
+	input := "line one\nline two\nline three\n"
+	cr := NewCountingReader(strings.NewReader(input))
+	buf := bufio.NewReaderSize(cr, 16)
+
+	line, _ := buf.ReadBytes('\n')
+	if offset := cr.Count() - int64(buf.Buffered()); offset != int64(len(line)) {
+		t.Errorf("Count() error: offset %d want %d", offset, len(line))
+	}
+	if cr.Count() != 16 { // the bufio.Reader has read a full buffer ahead
+		t.Errorf("Count() error: got %d want 16", cr.Count())
+	}
+}
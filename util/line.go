@@ -0,0 +1,44 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// ReadLine reads the next line from buf, accepting "\n", "\r\n" and bare "\r" line endings,
+// which can be mixed within the same input. The line is returned with its ending replaced by
+// a single "\n". Like bufio.Reader.ReadBytes, a final line without an ending is returned along
+// with io.EOF, and a nil error is returned only for a line with an ending.
+func ReadLine(buf *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		if buf.Buffered() == 0 {
+			if _, err := buf.Peek(1); err != nil {
+				return line, err
+			}
+		}
+		chunk, _ := buf.Peek(buf.Buffered())
+
+		end := len(chunk)
+		if i := bytes.IndexByte(chunk, '\n'); i != -1 {
+			end = i
+		}
+		if i := bytes.IndexByte(chunk[:end], '\r'); i != -1 {
+			end = i
+		}
+		line = append(line, chunk[:end]...)
+		if end == len(chunk) { // no line ending buffered yet
+			_, _ = buf.Discard(end)
+			continue
+		}
+
+		ending := chunk[end]
+		_, _ = buf.Discard(end + 1)
+		if ending == '\r' { // swallow the "\n" of a "\r\n" ending
+			if next, err := buf.Peek(1); err == nil && next[0] == '\n' {
+				_, _ = buf.Discard(1)
+			}
+		}
+		return append(line, '\n'), nil
+	}
+}
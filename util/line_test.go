@@ -0,0 +1,58 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output []string
+	}{{
+		Name:   "LF",
+		Input:  "a\nbb\nccc\n",
+		Output: []string{"a\n", "bb\n", "ccc\n"},
+	}, {
+		Name:   "CRLF",
+		Input:  "a\r\nbb\r\nccc\r\n",
+		Output: []string{"a\n", "bb\n", "ccc\n"},
+	}, {
+		Name:   "CR",
+		Input:  "a\rbb\rccc\r",
+		Output: []string{"a\n", "bb\n", "ccc\n"},
+	}, {
+		Name:   "Mixed",
+		Input:  "a\rbb\r\nccc\n\ndd",
+		Output: []string{"a\n", "bb\n", "ccc\n", "\n", "dd"},
+	}, {
+		Name:   "LongLine",
+		Input:  strings.Repeat("x", 100) + "\r\n" + strings.Repeat("y", 50),
+		Output: []string{strings.Repeat("x", 100) + "\n", strings.Repeat("y", 50)},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			buf := bufio.NewReaderSize(strings.NewReader(tt.Input), 16)
+			var lines []string
+			for {
+				line, err := ReadLine(buf)
+				if len(line) > 0 {
+					lines = append(lines, string(line))
+				}
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("ReadLine() error: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.Output) {
+				t.Errorf("ReadLine() error: unexpected lines\ngot \t%q\nwant \t%q", lines, tt.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package vcf
+
+import (
+	"sort"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+)
+
+// SupportedVersions are the ##fileformat versions that NewReader accepts
+var SupportedVersions = []string{"VCFv4.0", "VCFv4.1", "VCFv4.2", "VCFv4.3", "VCFv4.4"}
+
+// Capabilities describes what a Reader detected about its input, for debugging files that parse unexpectedly
+type Capabilities struct {
+	// Compression is "gzip" or "bgzf" for compressed files opened with OpenReader, and "none" otherwise
+	Compression string
+	// Version is the ##fileformat of the header, such as VCFv4.2
+	Version      string
+	HasGenotypes bool
+	Samples      int
+	// Meta lists the distinct meta-information keys of the header, such as INFO and contig, sorted
+	Meta []string
+}
+
+// Capabilities reports what the reader detected about its input while reading the header
+func (gr *Reader) Capabilities() Capabilities {
+	seen := make(map[string]bool)
+	for _, meta := range gr.Header.MetaOrder {
+		switch m := meta.(type) {
+		case *Meta:
+			seen[m.FieldType] = true
+		case *SingleValMeta:
+			seen[m.FieldType] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return Capabilities{
+		Compression:  fileio.Compression(gr.r),
+		Version:      gr.Header.FileFormat,
+		HasGenotypes: len(gr.Header.Genotypes) > 0,
+		Samples:      len(gr.Header.Genotypes),
+		Meta:         keys,
+	}
+}
@@ -0,0 +1,57 @@
+package vcf
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_Capabilities(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##source=myImputationProgramV3.1\n" +
+		"##contig=<ID=20,length=62435964>\n" +
+		"##INFO=<ID=NS,Number=1,Type=Integer,Description=\"Number of Samples With Data\">\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\tNA00003\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3\tGT\t0|0\t1|0\t1/1\n"
+
+	tests := []struct {
+		Name   string
+		File   string
+		Output Capabilities
+	}{{
+		Name: "Plain",
+		Output: Capabilities{Compression: "none", Version: "VCFv4.2", HasGenotypes: true, Samples: 3,
+			Meta: []string{"FORMAT", "INFO", "contig", "source"}},
+	}, {
+		Name: "Bgzf",
+		File: "in.vcf.bgz",
+		Output: Capabilities{Compression: "bgzf", Version: "VCFv4.2", HasGenotypes: true, Samples: 3,
+			Meta: []string{"FORMAT", "INFO", "contig", "source"}},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var r *Reader
+			var err error
+			if tt.File == "" {
+				r, err = NewReader(strings.NewReader(input))
+			} else {
+				path := filepath.Join(t.TempDir(), tt.File)
+				w, _ := OpenWriter(path)
+				_, _ = io.WriteString(w, input)
+				_ = w.Close()
+				r, err = OpenReader(path)
+			}
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			defer r.Close()
+			if got := r.Capabilities(); !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("Capabilities() error:\ngot \t%+v\nwant \t%+v", got, tt.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package vcf
+
+import "strings"
+
+// IsSNP reports whether the feature is a single nucleotide polymorphism, with a single base REF
+// and only single base ALT alleles. Structural variants are never SNPs, and missing ".",
+// overlapping deletion "*" and gVCF non-reference alleles are ignored, as by IsIndel and IsSV.
+func (f *Feature) IsSNP() bool {
+	alts := f.realAlts()
+	if len(f.Ref) != 1 || len(alts) == 0 || f.IsSV() {
+		return false
+	}
+	for _, alt := range alts {
+		if len(alt) != 1 || alt == f.Ref {
+			return false
+		}
+	}
+	return true
+}
+
+// IsIndel reports whether any ALT allele of the feature is an insertion or deletion,
+// differing in length from REF, and none are symbolic
+func (f *Feature) IsIndel() bool {
+	if f.IsSV() {
+		return false
+	}
+	for _, alt := range f.realAlts() {
+		if len(alt) != len(f.Ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSV reports whether the feature is a structural variant, with a symbolic ALT allele
+// such as <DEL>, a breakend such as G]17:198982], or an SVTYPE INFO field
+func (f *Feature) IsSV() bool {
+	if _, ok := f.Info["SVTYPE"]; ok {
+		return true
+	}
+	for _, alt := range f.realAlts() {
+		if isSymbolic(alt) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReferenceBlock reports whether the feature has no ALT allele other than a missing "." or the
+// gVCF non-reference allele, <NON_REF> or <*>, as in the reference blocks of a gVCF. Blocks cover
+// Pos up to their END INFO field, as given by EndOne.
+func (f *Feature) IsReferenceBlock() bool {
+	return len(f.realAlts()) == 0
+}
+
+// realAlts returns the ALT alleles, leaving out missing ".", overlapping deletion "*"
+// and gVCF non-reference alleles
+func (f *Feature) realAlts() []string {
+	alts := make([]string, 0, len(f.Alt))
+	for _, alt := range f.Alt {
+		switch alt {
+		case "", ".", "*", "<NON_REF>", "<*>":
+		default:
+			alts = append(alts, alt)
+		}
+	}
+	return alts
+}
+
+// isSymbolic reports whether an ALT allele is symbolic, such as <DEL>, or a breakend,
+// such as G]17:198982] or the single breakends .A and G.
+func isSymbolic(alt string) bool {
+	return strings.ContainsAny(alt, "<>[]") ||
+		len(alt) > 1 && (strings.HasPrefix(alt, ".") || strings.HasSuffix(alt, "."))
+}
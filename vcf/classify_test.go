@@ -0,0 +1,94 @@
+package vcf
+
+import "testing"
+
+func TestFeature_Classify(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input Feature
+		SNP   bool
+		Indel bool
+		SV    bool
+		Block bool
+	}{{
+		Name:  "SNP",
+		Input: Feature{Ref: "G", Alt: []string{"A"}},
+		SNP:   true,
+	}, {
+		Name:  "MultiallelicSNP",
+		Input: Feature{Ref: "G", Alt: []string{"A", "T"}},
+		SNP:   true,
+	}, {
+		Name:  "Deletion",
+		Input: Feature{Ref: "GTC", Alt: []string{"G"}},
+		Indel: true,
+	}, {
+		Name:  "Insertion",
+		Input: Feature{Ref: "A", Alt: []string{"ATG"}},
+		Indel: true,
+	}, {
+		Name:  "MNP",
+		Input: Feature{Ref: "AT", Alt: []string{"GC"}},
+	}, {
+		Name:  "MixedSNPAndIndel",
+		Input: Feature{Ref: "A", Alt: []string{"G", "AT"}},
+		Indel: true,
+	}, {
+		Name:  "SymbolicDeletion",
+		Input: Feature{Ref: "N", Alt: []string{"<DEL>"}},
+		SV:    true,
+	}, {
+		Name:  "Breakend",
+		Input: Feature{Ref: "G", Alt: []string{"G]17:198982]"}},
+		SV:    true,
+	}, {
+		Name:  "SingleBreakend",
+		Input: Feature{Ref: "G", Alt: []string{"G."}},
+		SV:    true,
+	}, {
+		Name:  "SVTYPE",
+		Input: Feature{Ref: "T", Alt: []string{"TAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}, Info: map[string]string{"SVTYPE": "INS"}},
+		SV:    true,
+	}, {
+		Name:  "MixedSNPAndSV",
+		Input: Feature{Ref: "A", Alt: []string{"G", "<DUP>"}},
+		SV:    true,
+	}, {
+		Name:  "SNPWithSpanningDeletion",
+		Input: Feature{Ref: "A", Alt: []string{"G", "*"}},
+		SNP:   true,
+	}, {
+		Name:  "NoVariant",
+		Input: Feature{Ref: "A", Alt: []string{"."}},
+		Block: true,
+	}, {
+		Name:  "NonRefBlock",
+		Input: Feature{Ref: "A", Alt: []string{"<NON_REF>"}, Info: map[string]string{"END": "14400"}},
+		Block: true,
+	}, {
+		Name:  "UnspecifiedAlleleBlock",
+		Input: Feature{Ref: "A", Alt: []string{"<*>"}},
+		Block: true,
+	}, {
+		Name:  "GVCFSNP",
+		Input: Feature{Ref: "A", Alt: []string{"G", "<NON_REF>"}},
+		SNP:   true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := tt.Input.IsSNP(); got != tt.SNP {
+				t.Errorf("IsSNP() error: got %v want %v", got, tt.SNP)
+			}
+			if got := tt.Input.IsIndel(); got != tt.Indel {
+				t.Errorf("IsIndel() error: got %v want %v", got, tt.Indel)
+			}
+			if got := tt.Input.IsSV(); got != tt.SV {
+				t.Errorf("IsSV() error: got %v want %v", got, tt.SV)
+			}
+			if got := tt.Input.IsReferenceBlock(); got != tt.Block {
+				t.Errorf("IsReferenceBlock() error: got %v want %v", got, tt.Block)
+			}
+		})
+	}
+}
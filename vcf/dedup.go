@@ -0,0 +1,55 @@
+package vcf
+
+import (
+	"io"
+	"strings"
+)
+
+// Dedup streams the features of r to w, collapsing consecutive features with the same
+// CHROM, POS, REF and ALT into one. Input is assumed to be sorted, so that duplicates are adjacent.
+//
+// If merge is nil the first of a run of duplicates is kept. Otherwise it is called with the
+// feature kept so far and each following duplicate, and returns the feature to keep in their place.
+// If w hasn't written a header yet, the header of r is written.
+func Dedup(r *Reader, w *Writer, merge func(kept, dup *Feature) *Feature) error {
+	if !w.Header {
+		if err := w.writeHeader(r.Header); err != nil {
+			return err
+		}
+	}
+
+	var kept *Feature
+	for {
+		f, err := r.Read()
+		if f != nil {
+			if kept != nil && sameVariant(kept, f) {
+				if merge != nil {
+					kept = merge(kept, f)
+				}
+			} else {
+				if kept != nil {
+					if werr := w.writeFeature(kept); werr != nil {
+						return werr
+					}
+				}
+				kept = f
+			}
+		}
+		if err != nil {
+			if kept != nil {
+				if werr := w.writeFeature(kept); werr != nil {
+					return werr
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// sameVariant reports whether two features have the same CHROM, POS, REF and ALT
+func sameVariant(a, b *Feature) bool {
+	return a.Chrom == b.Chrom && a.Pos == b.Pos && a.Ref == b.Ref && strings.Join(a.Alt, ",") == strings.Join(b.Alt, ",")
+}
@@ -0,0 +1,91 @@
+package vcf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	input := header +
+		"20\t100\tfirst\tG\tA\t29\tPASS\tDP=10\n" +
+		"20\t100\tsecond\tG\tA\t30\tPASS\tDP=5\n" +
+		"20\t100\tother\tG\tT\t29\tPASS\tDP=3\n" +
+		"20\t200\tthird\tC\tA\t29\tPASS\tDP=1\n" +
+		"20\t200\tfourth\tC\tA\t29\tPASS\tDP=2"
+
+	maxQual := func(kept, dup *Feature) *Feature {
+		if dup.Qual > kept.Qual {
+			return dup
+		}
+		return kept
+	}
+
+	tests := []struct {
+		Name   string
+		Merge  func(kept, dup *Feature) *Feature
+		Output string
+	}{{
+		Name: "KeepFirst",
+		Output: header +
+			"20\t100\tfirst\tG\tA\t29\tPASS\tDP=10\n" +
+			"20\t100\tother\tG\tT\t29\tPASS\tDP=3\n" +
+			"20\t200\tthird\tC\tA\t29\tPASS\tDP=1\n",
+	}, {
+		Name:  "Merge",
+		Merge: maxQual,
+		Output: header +
+			"20\t100\tsecond\tG\tA\t30\tPASS\tDP=5\n" +
+			"20\t100\tother\tG\tT\t29\tPASS\tDP=3\n" +
+			"20\t200\tthird\tC\tA\t29\tPASS\tDP=1\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			if err := Dedup(r, w, tt.Merge); err != nil {
+				t.Fatalf("Dedup() error: %v", err)
+			}
+			if got := b.String(); got != tt.Output {
+				t.Errorf("Dedup() error:\ngot \n%v \nwant \n%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestDedup_WriteError(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t100\tfirst\tG\tA\t29\tPASS\tDP=10\n" +
+		"20\t200\tsecond\tC\tA\t29\tPASS\tDP=1\n"
+	tests := []struct {
+		Name   string
+		Header bool
+	}{{
+		Name: "Header",
+	}, {
+		Name:   "Feature",
+		Header: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			w, _ := NewWriter(errWriter{})
+			w.Header = tt.Header
+			if err := Dedup(r, w, nil); !reflect.DeepEqual(err, errors.New("disk full")) {
+				t.Errorf("Dedup() error: unexpected error\ngot \t%v\nwant \t%v", err, errors.New("disk full"))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AlleleDepths returns the per-allele read depths of the AD field, REF first, with MissingIntegerValue
+// for any depth given as ".". A genotype without AD, or with AD ".", returns nil.
+func (g *Genotype) AlleleDepths() ([]int, error) {
+	val, ok := g.Fields["AD"]
+	if !ok || val == "." || val == "" {
+		return nil, nil
+	}
+	vals := strings.Split(val, ",")
+	depths := make([]int, len(vals))
+	for i, v := range vals {
+		if v == "." {
+			depths[i] = MissingIntegerValue
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid AD value %q", v)
+		}
+		depths[i] = n
+	}
+	return depths, nil
+}
+
+// DepthConsistent reports whether the AD field has one depth for each of numAlleles alleles (REF and ALTs),
+// and its depths sum to no more than DP, since reads that support no allele are counted by DP but not AD.
+// Missing depths are left out of the sum. A genotype missing AD or DP has nothing to contradict, so is
+// consistent as far as it goes; an error is only returned if AD or DP doesn't parse.
+func (g *Genotype) DepthConsistent(numAlleles int) (bool, error) {
+	depths, err := g.AlleleDepths()
+	if err != nil {
+		return false, err
+	}
+	dp, hasDP := g.Fields["DP"]
+	hasDP = hasDP && dp != "." && dp != ""
+	var total int
+	if hasDP {
+		if total, err = strconv.Atoi(dp); err != nil {
+			return false, fmt.Errorf("invalid DP value %q", dp)
+		}
+	}
+
+	if depths != nil && len(depths) != numAlleles {
+		return false, nil
+	}
+	if !hasDP || depths == nil {
+		return true, nil
+	}
+	sum := 0
+	for _, d := range depths {
+		if d != MissingIntegerValue {
+			sum += d
+		}
+	}
+	return sum <= total, nil
+}
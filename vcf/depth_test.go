@@ -0,0 +1,91 @@
+package vcf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenotype_DepthConsistent(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Input      map[string]string
+		NumAlleles int
+		Depths     []int
+		Output     bool
+		Error      error
+	}{{
+		Name:       "Consistent",
+		Input:      map[string]string{"GT": "0/1", "AD": "10,5", "DP": "17"},
+		NumAlleles: 2,
+		Depths:     []int{10, 5},
+		Output:     true,
+	}, {
+		Name:       "Equal",
+		Input:      map[string]string{"AD": "10,5,0", "DP": "15"},
+		NumAlleles: 3,
+		Depths:     []int{10, 5, 0},
+		Output:     true,
+	}, {
+		Name:       "ExceedsDP",
+		Input:      map[string]string{"AD": "10,8", "DP": "15"},
+		NumAlleles: 2,
+		Depths:     []int{10, 8},
+		Output:     false,
+	}, {
+		Name:       "WrongArity",
+		Input:      map[string]string{"AD": "10,5", "DP": "17"},
+		NumAlleles: 3,
+		Depths:     []int{10, 5},
+		Output:     false,
+	}, {
+		Name:       "MissingDepth",
+		Input:      map[string]string{"AD": "10,.", "DP": "12"},
+		NumAlleles: 2,
+		Depths:     []int{10, MissingIntegerValue},
+		Output:     true,
+	}, {
+		Name:       "MissingAD",
+		Input:      map[string]string{"AD": ".", "DP": "12"},
+		NumAlleles: 2,
+		Output:     true,
+	}, {
+		Name:       "MissingDP",
+		Input:      map[string]string{"AD": "10,5"},
+		NumAlleles: 2,
+		Depths:     []int{10, 5},
+		Output:     true,
+	}, {
+		Name:       "InvalidAD",
+		Input:      map[string]string{"AD": "10,x", "DP": "12"},
+		NumAlleles: 2,
+		Error:      errors.New(`invalid AD value "x"`),
+	}, {
+		Name:       "InvalidDP",
+		Input:      map[string]string{"AD": "10,5", "DP": "many"},
+		NumAlleles: 2,
+		Depths:     []int{10, 5},
+		Error:      errors.New(`invalid DP value "many"`),
+	}, {
+		Name:       "InvalidDPWithoutAD",
+		Input:      map[string]string{"DP": "abc"},
+		NumAlleles: 2,
+		Error:      errors.New(`invalid DP value "abc"`),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			g := &Genotype{Fields: tt.Input}
+			if depths, _ := g.AlleleDepths(); !reflect.DeepEqual(depths, tt.Depths) {
+				t.Errorf("AlleleDepths() error:\ngot \t%v\nwant \t%v", depths, tt.Depths)
+			}
+			ok, err := g.DepthConsistent(tt.NumAlleles)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("DepthConsistent() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if ok != tt.Output {
+				t.Errorf("DepthConsistent() error:\ngot \t%v\nwant \t%v", ok, tt.Output)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -42,6 +43,7 @@ type Header struct {
 	Others     []*Meta
 	SingleVals []*SingleValMeta
 	PrintOrder []*Meta
+	MetaOrder  []fmt.Stringer // every meta line, single or structured, in file order
 	FileFormat string
 	Genotypes  map[string]uint64
 }
@@ -58,6 +60,7 @@ type Feature struct {
 	Filter          string
 	Info            map[string]string
 	InfoOrder       map[string]int
+	Flags           map[string]bool // INFO keys given without a value, which are also in Info as Info[key] = key
 	Format          map[string]int
 	Genotypes       [][]byte
 	ParsedGenotypes map[string]*Genotype
@@ -65,12 +68,39 @@ type Feature struct {
 
 const MissingQualField = math.MaxFloat64
 
+// IsFlag reports whether the INFO key is a flag, given without a value.
+// Features without Flags, such as those built by hand, treat any key whose value is the key itself as a flag.
+func (f *Feature) IsFlag(key string) bool {
+	if f.Flags != nil {
+		return f.Flags[key]
+	}
+	val, ok := f.Info[key]
+	return ok && val == key
+}
+
 // Genotype represents a single genotype variant in a Feature
 type Genotype struct {
 	Id       string
 	GT       []int
 	PhasedGT bool
 	Fields   map[string]string
+
+	column uint64 // index into Feature.Genotypes
+	dirty  bool   // changed by SetField since the feature was last synced
+}
+
+// SetField sets the genotype field key to value, updating GT and PhasedGT if key is "GT".
+// The change is written back to the feature's raw Genotypes by Feature.SyncGenotypes,
+// and is included when the feature is written, whether or not it has been synced.
+func (g *Genotype) SetField(key, value string) {
+	if g.Fields == nil {
+		g.Fields = make(map[string]string)
+	}
+	g.Fields[key] = value
+	if key == "GT" {
+		g.GT, g.PhasedGT = parseGT([]byte(value))
+	}
+	g.dirty = true
 }
 
 // OptionalToString returns string representation of any meta directive field that isn't
@@ -82,7 +112,7 @@ func (m *Meta) optionalToString() string {
 
 	for _, opt := range fo {
 		if val, ok := m.Optional[opt]; ok {
-			b.WriteString(fmt.Sprintf("%s=%s,", opt, val))
+			b.WriteString(fmt.Sprintf("%s=%s,", opt, metaValue(val, false)))
 		}
 	}
 
@@ -98,9 +128,12 @@ func (m *Meta) String() string {
 	var b = bytes.Buffer{}
 	if len(m.FieldOrder) == 0 { //Generate DefaultFieldOrder if there is none
 		m.FieldOrder = append(m.FieldOrder, "ID", "Number", "Type", "Description", "URL")
-		for _, field := range m.Optional {
-			m.FieldOrder = append(m.FieldOrder, field)
+		optional := make([]string, 0, len(m.Optional))
+		for field := range m.Optional {
+			optional = append(optional, field)
 		}
+		sort.Strings(optional)
+		m.FieldOrder = append(m.FieldOrder, optional...)
 	}
 	b.WriteString(fmt.Sprintf("##%s=<", m.FieldType))
 	for _, field := range m.FieldOrder {
@@ -119,7 +152,7 @@ func (m *Meta) String() string {
 			}
 		case "Description":
 			if m.Description != "" {
-				b.WriteString(fmt.Sprintf("%s=%s,", field, m.Description))
+				b.WriteString(fmt.Sprintf("%s=%s,", field, metaValue(m.Description, true)))
 			}
 		case "URL":
 			if m.Url != "" {
@@ -157,10 +190,163 @@ func NewHeader() *Header {
 	h.Others = make([]*Meta, 0)
 	h.SingleVals = make([]*SingleValMeta, 0)
 	h.PrintOrder = make([]*Meta, 0)
+	h.MetaOrder = make([]fmt.Stringer, 0)
 	h.Genotypes = make(map[string]uint64)
 	return &h
 }
 
+// String returns the complete header block: the ##fileformat line, every meta line,
+// and the #CHROM line with any samples, each terminated by a newline
+func (h *Header) String() string {
+	var b = bytes.Buffer{}
+	_, _ = fmt.Fprintf(&b, "##fileformat=%s\n", h.FileFormat)
+	// Print meta lines in their original order, then any added to SingleVals or PrintOrder but not MetaOrder
+	listed := make(map[fmt.Stringer]bool, len(h.MetaOrder))
+	for _, val := range h.MetaOrder {
+		listed[val] = true
+		_, _ = fmt.Fprintf(&b, "%s\n", val)
+	}
+	for _, val := range h.SingleVals { // Print all ##key=value lines
+		if !listed[val] {
+			_, _ = fmt.Fprintf(&b, "%s\n", val)
+		}
+	}
+	for _, val := range h.PrintOrder { // Print all ##key=<key=value> lines
+		if !listed[val] {
+			_, _ = fmt.Fprintf(&b, "%s\n", val)
+		}
+	}
+
+	b.WriteString("#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO")
+	if len(h.Genotypes) > 0 {
+		gt := make([]string, len(h.Genotypes))
+		for key, val := range h.Genotypes {
+			gt[val] = key
+		}
+		_, _ = fmt.Fprintf(&b, "\tFORMAT\t%s", strings.Join(gt, "\t"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Version returns the major and minor version of the ##fileformat line, such as 4 and 2 for VCFv4.2
+func (h *Header) Version() (major, minor int, err error) {
+	invalid := fmt.Errorf("invalid fileformat %q", h.FileFormat)
+	if !strings.HasPrefix(h.FileFormat, "VCFv") {
+		return 0, 0, invalid
+	}
+	parts := strings.Split(strings.TrimPrefix(h.FileFormat, "VCFv"), ".")
+	if len(parts) != 2 {
+		return 0, 0, invalid
+	}
+	if major, err = strconv.Atoi(parts[0]); err != nil || major < 0 {
+		return 0, 0, invalid
+	}
+	if minor, err = strconv.Atoi(parts[1]); err != nil || minor < 0 {
+		return 0, 0, invalid
+	}
+	return major, minor, nil
+}
+
+// AddInfo appends an ##INFO=<ID,Number,Type,Description> meta directive to the header, replacing any with the same ID
+func (h *Header) AddInfo(id, number, typ, description string) *Meta {
+	meta := &Meta{FieldType: "INFO", Id: id, Number: number, Type: typ, Description: quoteDescription(description)}
+	meta.FieldOrder = []string{"ID", "Number", "Type", "Description"}
+	h.addMeta(&h.Infos, meta)
+	return meta
+}
+
+// AddFormat appends a ##FORMAT=<ID,Number,Type,Description> meta directive to the header, replacing any with the same ID
+func (h *Header) AddFormat(id, number, typ, description string) *Meta {
+	meta := &Meta{FieldType: "FORMAT", Id: id, Number: number, Type: typ, Description: quoteDescription(description)}
+	meta.FieldOrder = []string{"ID", "Number", "Type", "Description"}
+	h.addMeta(&h.Formats, meta)
+	return meta
+}
+
+// AddFilter appends a ##FILTER=<ID,Description> meta directive to the header, replacing any with the same ID
+func (h *Header) AddFilter(id, description string) *Meta {
+	meta := &Meta{FieldType: "FILTER", Id: id, Description: quoteDescription(description)}
+	meta.FieldOrder = []string{"ID", "Description"}
+	h.addMeta(&h.Filters, meta)
+	return meta
+}
+
+// AddContig appends a ##contig=<ID,length> meta directive to the header, replacing any with the same ID
+func (h *Header) AddContig(id string, length uint64) *Meta {
+	meta := &Meta{FieldType: "contig", Id: id}
+	meta.Optional = map[string]string{"length": strconv.FormatUint(length, 10)}
+	meta.FieldOrder = []string{"ID", "length"}
+	h.addMeta(&h.Contigs, meta)
+	return meta
+}
+
+// SampleDefinition returns the ##SAMPLE meta line with the given ID, and false if there isn't one
+func (h *Header) SampleDefinition(id string) (*Meta, bool) {
+	meta := findMeta(h.Samples, id)
+	return meta, meta != nil
+}
+
+// SampleDefinitionIDs returns the IDs of the ##SAMPLE meta lines, in header order.
+// These describe samples, and need not match the genotype columns of the #CHROM line.
+func (h *Header) SampleDefinitionIDs() []string {
+	ids := make([]string, 0, len(h.Samples))
+	for _, meta := range h.Samples {
+		ids = append(ids, meta.Id)
+	}
+	return ids
+}
+
+// addMeta adds a structured meta directive to list, such as Infos, and to the header's print order.
+// A meta already in list with the same ID is replaced in place, wherever it is listed. Otherwise the
+// meta is appended, to MetaOrder too unless the header has metas but no MetaOrder, in which case
+// they are all written in the order of SingleVals and PrintOrder.
+func (h *Header) addMeta(list *[]*Meta, meta *Meta) {
+	if old := findMeta(*list, meta.Id); old != nil {
+		replaceMeta(*list, old, meta)
+		replaceMeta(h.PrintOrder, old, meta)
+		for i, val := range h.MetaOrder {
+			if val == fmt.Stringer(old) {
+				h.MetaOrder[i] = meta
+			}
+		}
+		return
+	}
+	*list = append(*list, meta)
+	if len(h.MetaOrder) > 0 || len(h.SingleVals) == 0 && len(h.PrintOrder) == 0 {
+		h.MetaOrder = append(h.MetaOrder, meta)
+	}
+	h.PrintOrder = append(h.PrintOrder, meta)
+}
+
+// replaceMeta replaces old with meta in list
+func replaceMeta(list []*Meta, old, meta *Meta) {
+	for i, val := range list {
+		if val == old {
+			list[i] = meta
+		}
+	}
+}
+
+// metaValue returns a structured meta value as written, quoted and escaped if quote is set or it contains
+// characters that would otherwise end the value. Values read from a file keep their quotes, and are written as-is.
+func metaValue(val string, quote bool) string {
+	if len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"' {
+		return val
+	}
+	if quote || strings.ContainsAny(val, " ,\"=<>") {
+		return quoteDescription(val)
+	}
+	return val
+}
+
+// quoteDescription wraps a description in double quotes, escaping any quotes or backslashes within it
+func quoteDescription(description string) string {
+	description = strings.ReplaceAll(description, `\`, `\\`)
+	description = strings.ReplaceAll(description, `"`, `\"`)
+	return `"` + description + `"`
+}
+
 // StartZero returns Feature.Start in zero based coordinate systems
 func (f *Feature) StartZero() uint64 {
 	if f.Pos == 0 {
@@ -171,7 +357,10 @@ func (f *Feature) StartZero() uint64 {
 
 // EndZero returns Feature.End in zero based coordinate systems
 func (f *Feature) EndZero() uint64 {
-	return f.StartZero()
+	if end := f.EndOne(); end > 0 {
+		return end - 1
+	}
+	return 0
 }
 
 // StartOne returns Feature.Start in one based coordinate systems (gff3 spec default)
@@ -179,43 +368,43 @@ func (f *Feature) StartOne() uint64 {
 	return f.Pos
 }
 
-// EndOne returns Feature.End in one based coordinate systems (gff3 spec default)
+// EndOne returns Feature.End in one based coordinate systems (gff3 spec default), the last reference
+// base covered by the feature. This is the END INFO field if the feature has one, as gVCF reference
+// blocks and structural variants do, and otherwise the last base of REF.
 func (f *Feature) EndOne() uint64 {
+	if val, ok := f.Info["END"]; ok {
+		if end, err := strconv.ParseUint(val, 10, 64); err == nil && end >= f.Pos {
+			return end
+		}
+	}
+	if len(f.Ref) > 1 {
+		return f.Pos + uint64(len(f.Ref)) - 1
+	}
 	return f.StartOne()
 }
 
-//SingleGenotype returns a pointer to a Genotype or an error
+//SingleGenotype returns a pointer to a Genotype or an error.
+//The Genotype for each sample is parsed once and cached in ParsedGenotypes, so features aren't safe to
+//share between goroutines until ParseGenotypes has filled the cache, after which SingleGenotype only reads it.
 func (f *Feature) SingleGenotype(gen string, order map[string]uint64) (*Genotype, error) {
 	if loc, ok := order[gen]; ok { //gen is a valid genotype
 		if preParsed, ok := f.ParsedGenotypes[gen]; ok { //gen has already been accessed for this feature
 			return preParsed, nil
+		} else if loc >= uint64(len(f.Genotypes)) { //order points past the genotype columns of this feature
+			return nil, errors.New("genotype column not in feature")
 		} else { //gen needs to be extracted from the info field
-			info := bytes.Split(f.Genotypes[loc], []byte{':'})
+			// a trailing CR or spaces, such as from a CRLF file, would otherwise end up in the last field
+			info := bytes.Split(bytes.TrimRight(f.Genotypes[loc], " \t\r\n"), []byte{':'})
 			if len(info) != len(f.Format) { //info is improperly formatted
 				return nil, errors.New("genotype has improperly formatted data")
 			} else {
-				parsedGT := Genotype{PhasedGT: false}
+				parsedGT := Genotype{PhasedGT: false, column: loc}
 				parsedGT.Id = gen
 				parsedGT.Fields = make(map[string]string, len(f.Format))
 				for key, value := range f.Format {
 					parsedGT.Fields[key] = string(info[value])
 					if key == "GT" {
-						gt := bytes.Split(info[value], []byte{'|'})
-						if len(gt) > 1 {
-							parsedGT.PhasedGT = true
-						} else if len(gt) == 1 {
-							gt = bytes.Split(info[value], []byte{'/'})
-						}
-
-						parsedGT.GT = make([]int, len(gt))
-						for i := range gt {
-							if string(gt[i]) == "." {
-								parsedGT.GT[i] = -1
-							} else {
-								val, _ := strconv.Atoi(string(gt[i]))
-								parsedGT.GT[i] = val
-							}
-						}
+						parsedGT.GT, parsedGT.PhasedGT = parseGT(info[value])
 					}
 				}
 				if len(f.ParsedGenotypes) == 0 {
@@ -230,6 +419,193 @@ func (f *Feature) SingleGenotype(gen string, order map[string]uint64) (*Genotype
 	}
 }
 
+// parseGT returns the allele indices of a GT field, with -1 for missing alleles, and whether it is phased
+func parseGT(field []byte) ([]int, bool) {
+	phased := false
+	gt := bytes.Split(field, []byte{'|'})
+	if len(gt) > 1 {
+		phased = true
+	} else if len(gt) == 1 {
+		gt = bytes.Split(field, []byte{'/'})
+	}
+
+	alleles := make([]int, len(gt))
+	for i := range gt {
+		if string(gt[i]) == "." {
+			alleles[i] = -1
+		} else {
+			val, _ := strconv.Atoi(string(gt[i]))
+			alleles[i] = val
+		}
+	}
+	return alleles, phased
+}
+
+// SyncGenotypes writes the genotypes changed by Genotype.SetField back to the raw Genotypes.
+// Fields that are new to the feature are added to the end of Format, in sorted order, and given
+// the missing value "." in every other genotype. Writers include the changes without syncing.
+func (f *Feature) SyncGenotypes() {
+	format, genotypes, dirty := f.synced()
+	if len(dirty) == 0 {
+		return
+	}
+	f.Format, f.Genotypes = format, genotypes
+	for _, gt := range dirty {
+		gt.dirty = false
+	}
+}
+
+// synced returns the Format and Genotypes that SyncGenotypes would give f, along with the genotypes
+// changed by SetField, without changing f. Without changes, Format and Genotypes are returned as they are.
+func (f *Feature) synced() (map[string]int, [][]byte, []*Genotype) {
+	var dirty []*Genotype
+	for _, gt := range f.ParsedGenotypes {
+		if gt.dirty {
+			dirty = append(dirty, gt)
+		}
+	}
+	if len(dirty) == 0 {
+		return f.Format, f.Genotypes, nil
+	}
+
+	var added []string
+	seen := make(map[string]bool)
+	for _, gt := range dirty {
+		for key := range gt.Fields {
+			if _, ok := f.Format[key]; !ok && !seen[key] {
+				seen[key] = true
+				added = append(added, key)
+			}
+		}
+	}
+	format, genotypes := addFormatKeys(f.Format, f.Genotypes, added)
+
+	for _, gt := range dirty {
+		vals := make([]string, len(format))
+		for key, i := range format {
+			if val, ok := gt.Fields[key]; ok {
+				vals[i] = val
+			} else {
+				vals[i] = "."
+			}
+		}
+		if gt.column < uint64(len(genotypes)) {
+			genotypes[gt.column] = []byte(strings.Join(vals, ":"))
+		}
+	}
+	return format, genotypes, dirty
+}
+
+// addFormatKeys returns copies of format and genotypes with the keys in added, which are new to format,
+// appended to format in sorted order and given the missing value "." in every column. GT, which must
+// come first, is put before the existing keys instead.
+func addFormatKeys(format map[string]int, genotypes [][]byte, added []string) (map[string]int, [][]byte) {
+	sort.Strings(added)
+	shift := 0
+	for i, key := range added {
+		if key == "GT" {
+			shift, added = 1, append(added[:i:i], added[i+1:]...)
+			break
+		}
+	}
+
+	grown := make(map[string]int, len(format)+len(added)+shift)
+	for key, i := range format {
+		grown[key] = i + shift
+	}
+	if shift == 1 {
+		grown["GT"] = 0
+	}
+	for _, key := range added {
+		grown[key] = len(grown)
+	}
+
+	columns := make([][]byte, len(genotypes))
+	for i, raw := range genotypes {
+		if len(grown) == len(format) {
+			columns[i] = raw
+			continue
+		}
+		vals := strings.Split(string(missingGenotype(len(grown))), ":")
+		if len(format) > 0 {
+			copy(vals[shift:shift+len(format)], strings.Split(string(raw), ":"))
+		}
+		columns[i] = []byte(strings.Join(vals, ":"))
+	}
+	return grown, columns
+}
+
+// SetGenotype writes g to the genotype column of sample, the write-side counterpart of SingleGenotype,
+// for building features from scratch. Genotypes is grown to a column for each sample in order if
+// needed, with every value of new columns missing. Fields of g that are new to the feature are appended
+// to Format in sorted order, and given the missing value "." in the other columns. GT, which must come
+// first, is put before any existing keys instead. GT is written from g.GT and g.PhasedGT if g has no GT field.
+func (f *Feature) SetGenotype(sample string, g *Genotype, order map[string]uint64) error {
+	loc, ok := order[sample]
+	if !ok {
+		return errors.New("genotype not in vcf")
+	}
+	fields := make(map[string]string, len(g.Fields)+1)
+	for key, val := range g.Fields {
+		fields[key] = val
+	}
+	if _, ok := fields["GT"]; !ok && len(g.GT) > 0 {
+		fields["GT"] = formatGT(g.GT, g.PhasedGT)
+	}
+
+	f.SyncGenotypes() // so that pending SetField changes aren't lost to the new Format
+
+	var added []string
+	for key := range fields {
+		if _, ok := f.Format[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	f.Format, f.Genotypes = addFormatKeys(f.Format, f.Genotypes, added)
+
+	columns := uint64(len(order))
+	if loc >= columns {
+		columns = loc + 1
+	}
+	for uint64(len(f.Genotypes)) < columns {
+		f.Genotypes = append(f.Genotypes, missingGenotype(len(f.Format)))
+	}
+
+	vals := make([]string, len(f.Format))
+	for key, i := range f.Format {
+		if val, ok := fields[key]; ok {
+			vals[i] = val
+		} else {
+			vals[i] = "."
+		}
+	}
+	f.Genotypes[loc] = []byte(strings.Join(vals, ":"))
+	delete(f.ParsedGenotypes, sample) // parsed again by SingleGenotype
+	return nil
+}
+
+// formatGT returns the GT field for allele indices, with -1 for missing alleles
+func formatGT(alleles []int, phased bool) string {
+	sep := "/"
+	if phased {
+		sep = "|"
+	}
+	gt := make([]string, len(alleles))
+	for i, allele := range alleles {
+		if allele < 0 {
+			gt[i] = "."
+		} else {
+			gt[i] = strconv.Itoa(allele)
+		}
+	}
+	return strings.Join(gt, sep)
+}
+
+// missingGenotype returns a genotype column with n missing values
+func missingGenotype(n int) []byte {
+	return bytes.TrimSuffix(bytes.Repeat([]byte(".:"), n), []byte{':'})
+}
+
 //MultipleGenotypes returns an array of pointers to genotypes, along with an array of errors
 func (f *Feature) MultipleGenotypes(gens []string, order map[string]uint64) ([]*Genotype, []error) {
 	gts := make([]*Genotype, len(gens))
@@ -242,11 +618,68 @@ func (f *Feature) MultipleGenotypes(gens []string, order map[string]uint64) ([]*
 	return gts, errs
 }
 
-//AllGenotypes returns an array of pointers to all genotypes, along with any errors
+// ParseGenotypes parses the genotype of every sample in order into ParsedGenotypes, returning the
+// first error. Features are safe to share between goroutines calling SingleGenotype once it has run,
+// as long as none of them change genotypes with SetField, SetGenotype or SyncGenotypes.
+func (f *Feature) ParseGenotypes(order map[string]uint64) error {
+	_, errs := f.AllGenotypes(order)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//AllGenotypes returns an array of pointers to all genotypes in column order, along with any errors.
+//order doesn't need to cover every column, or to be contiguous.
 func (f *Feature) AllGenotypes(order map[string]uint64) ([]*Genotype, []error) {
-	gts := make([]string, len(order))
-	for gt, i := range order {
-		gts[i] = gt
+	gts := make([]string, 0, len(order))
+	for gt := range order {
+		gts = append(gts, gt)
 	}
+	sort.Slice(gts, func(i, j int) bool {
+		return order[gts[i]] < order[gts[j]]
+	})
 	return f.MultipleGenotypes(gts, order)
 }
+
+// GTMatrix returns the GT allele indices of every genotype in order, in column order,
+// as one row per sample and one column per allele, with -1 for missing alleles.
+//
+// Every row is as long as the highest ploidy at the site. A sample with fewer alleles
+// is an error, unless padRagged is set, in which case its row is padded with -1.
+// A lone missing call "." is always padded.
+func (f *Feature) GTMatrix(order map[string]uint64, padRagged bool) ([][]int, error) {
+	if _, ok := f.Format["GT"]; !ok {
+		return nil, errors.New("GT not in FORMAT")
+	}
+	gts, errs := f.AllGenotypes(order)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ploidy := 0
+	for _, gt := range gts {
+		if len(gt.GT) > ploidy {
+			ploidy = len(gt.GT)
+		}
+	}
+
+	cells := make([]int, len(gts)*ploidy) // rows share one backing array
+	matrix := make([][]int, len(gts))
+	for i, gt := range gts {
+		missing := len(gt.GT) == 1 && gt.GT[0] == -1
+		if len(gt.GT) != ploidy && !padRagged && !missing {
+			return nil, fmt.Errorf("genotype %s has ploidy %d, expected %d", gt.Id, len(gt.GT), ploidy)
+		}
+		row := cells[i*ploidy : (i+1)*ploidy : (i+1)*ploidy]
+		for j := copy(row, gt.GT); j < ploidy; j++ {
+			row[j] = -1
+		}
+		matrix[i] = row
+	}
+	return matrix, nil
+}
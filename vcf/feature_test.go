@@ -1,9 +1,13 @@
 package vcf
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -62,6 +66,40 @@ func TestFeaturePositions(t *testing.T) {
 			StartOne:  0,
 		},
 		Error: io.EOF,
+	}, {
+		Name: "ReferenceBlock",
+		Input: Feature{
+			Chrom:  "20",
+			Pos:    14370,
+			Id:     ".",
+			Ref:    "G",
+			Alt:    []string{"<NON_REF>"},
+			Filter: ".",
+			Info:   map[string]string{"END": "14400"},
+		},
+		Output: FeaturePos{
+			EndZero:   14399,
+			StartZero: 14369,
+			EndOne:    14400,
+			StartOne:  14370,
+		},
+	}, {
+		Name: "Deletion",
+		Input: Feature{
+			Chrom:  "20",
+			Pos:    14370,
+			Id:     ".",
+			Ref:    "GTC",
+			Alt:    []string{"G"},
+			Filter: ".",
+			Info:   map[string]string{},
+		},
+		Output: FeaturePos{
+			EndZero:   14371,
+			StartZero: 14369,
+			EndOne:    14372,
+			StartOne:  14370,
+		},
 	}}
 
 	for _, tt := range tests {
@@ -84,7 +122,7 @@ func TestFeature_SingleGenotype(t *testing.T) {
 	tests := []struct {
 		Name    string
 		Input   Feature
-		GTOrder map[string]int
+		GTOrder map[string]uint64
 		Output  Genotype
 		Error   error
 	}{{
@@ -101,7 +139,7 @@ func TestFeature_SingleGenotype(t *testing.T) {
 			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
 			Genotypes: [][]byte{{48, 124, 48, 58, 52, 56, 58, 49, 58, 53, 49, 44, 53, 49}},
 		},
-		GTOrder: map[string]int{"NA0001": 0},
+		GTOrder: map[string]uint64{"NA0001": 0},
 		Output: Genotype{
 			Id:       "NA0001",
 			GT:       []int{0, 0},
@@ -123,7 +161,7 @@ func TestFeature_SingleGenotype(t *testing.T) {
 			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
 			Genotypes: [][]byte{{48, 124, 48, 58, 52, 56, 58, 49, 58, 53, 49, 44, 53, 49}},
 		},
-		GTOrder: map[string]int{"NA0002": 0},
+		GTOrder: map[string]uint64{"NA0002": 0},
 		Error:   errors.New("genotype not in vcf"),
 	}, {
 		Name: "TooManyFormatLines",
@@ -139,8 +177,30 @@ func TestFeature_SingleGenotype(t *testing.T) {
 			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
 			Genotypes: [][]byte{{48, 58, 48, 124, 48, 58, 52, 56, 58, 49, 58, 53, 49, 44, 53, 49}},
 		},
-		GTOrder: map[string]int{"NA0001": 0},
+		GTOrder: map[string]uint64{"NA0001": 0},
 		Error:   errors.New("genotype has improperly formatted data"),
+	}, {
+		Name: "TrailingWhitespace",
+		Input: Feature{
+			Chrom:     "20",
+			Pos:       14370,
+			Id:        "trs6054257",
+			Ref:       "G",
+			Alt:       []string{"A"},
+			Qual:      29,
+			Filter:    "PASS",
+			Info:      map[string]string{"NS": "3", "DP": "14", "AF": "0.5", "DB": "DB", "H2": "H2"},
+			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
+			Genotypes: [][]byte{[]byte("0|0:48:1:51,51 \r")},
+		},
+		GTOrder: map[string]uint64{"NA0001": 0},
+		Output: Genotype{
+			Id:       "NA0001",
+			GT:       []int{0, 0},
+			PhasedGT: true,
+			Fields:   map[string]string{"GT": "0|0", "GQ": "48", "DP": "1", "HQ": "51,51"},
+		},
+		Error: nil,
 	}, {
 		Name: "AlreadyParsed",
 		Input: Feature{
@@ -155,7 +215,7 @@ func TestFeature_SingleGenotype(t *testing.T) {
 			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
 			Genotypes: [][]byte{{48, 124, 48, 58, 52, 56, 58, 49, 58, 53, 49, 44, 53, 49}},
 		},
-		GTOrder: map[string]int{"NA0001": 0},
+		GTOrder: map[string]uint64{"NA0001": 0},
 		Output: Genotype{
 			Id:       "NA0001",
 			GT:       []int{0, 0},
@@ -178,6 +238,9 @@ func TestFeature_SingleGenotype(t *testing.T) {
 				out := reflect.ValueOf(*r)
 				exp := reflect.ValueOf(tt.Output)
 				for i := 0; i < out.NumField(); i++ {
+					if !out.Field(i).CanInterface() { // skip unexported bookkeeping
+						continue
+					}
 					got := out.Field(i).Interface()
 					expt := exp.Field(i).Interface()
 					if !reflect.DeepEqual(got, expt) {
@@ -191,3 +254,286 @@ func TestFeature_SingleGenotype(t *testing.T) {
 		})
 	}
 }
+
+func TestHeader_Add(t *testing.T) {
+	h := NewHeader()
+	h.FileFormat = "VCFv4.2"
+	h.AddInfo("NS", "1", "Integer", "Number of Samples With Data")
+	h.AddFilter("q10", "Quality below 10")
+	h.AddFormat("GT", "1", "String", `Genotype, "phased" or not`)
+	h.AddContig("20", 62435964)
+	h.AddInfo("NS", "1", "Integer", "Number of samples")
+
+	if len(h.Infos) != 1 || len(h.Filters) != 1 || len(h.Formats) != 1 || len(h.Contigs) != 1 || len(h.PrintOrder) != 4 || len(h.MetaOrder) != 4 {
+		t.Fatalf("Add() error: metas not added to header\ngot \t%v", h)
+	}
+
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteHeader(*h)
+	want := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=NS,Number=1,Type=Integer,Description=\"Number of samples\">\n" +
+		"##FILTER=<ID=q10,Description=\"Quality below 10\">\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype, \\\"phased\\\" or not\">\n" +
+		"##contig=<ID=20,length=62435964>\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	if got := b.String(); got != want {
+		t.Errorf("Add() error: unexpected header\ngot \n%v \nwant \n%v", got, want)
+	}
+}
+
+func TestFeature_AllGenotypes(t *testing.T) {
+	f := Feature{
+		Format:    map[string]int{"GT": 0, "GQ": 1},
+		Genotypes: [][]byte{[]byte("0|0:48"), []byte("1|0:48"), []byte("1/1:43")},
+	}
+	tests := []struct {
+		Name   string
+		Order  map[string]uint64
+		Output []string
+		Errors []error
+	}{{
+		Name:   "Contiguous",
+		Order:  map[string]uint64{"NA00003": 2, "NA00001": 0, "NA00002": 1},
+		Output: []string{"NA00001", "NA00002", "NA00003"},
+		Errors: []error{nil, nil, nil},
+	}, {
+		Name:   "Sparse",
+		Order:  map[string]uint64{"NA00003": 2, "NA00001": 0},
+		Output: []string{"NA00001", "NA00003"},
+		Errors: []error{nil, nil},
+	}, {
+		Name:   "PastEnd",
+		Order:  map[string]uint64{"NA00009": 9, "NA00002": 1},
+		Output: []string{"NA00002", ""},
+		Errors: []error{nil, errors.New("genotype column not in feature")},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gts, errs := f.AllGenotypes(tt.Order)
+			var ids []string
+			for _, gt := range gts {
+				if gt == nil {
+					ids = append(ids, "")
+				} else {
+					ids = append(ids, gt.Id)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.Output) {
+				t.Errorf("AllGenotypes() error: unexpected order\ngot \t%v\nwant \t%v", ids, tt.Output)
+			}
+			if !reflect.DeepEqual(errs, tt.Errors) {
+				t.Errorf("AllGenotypes() error: unexpected errors\ngot \t%v\nwant \t%v", errs, tt.Errors)
+			}
+		})
+	}
+}
+
+// TestFeature_SingleGenotypeConcurrent is meant to be run with -race
+func TestFeature_SingleGenotypeConcurrent(t *testing.T) {
+	f := Feature{Format: map[string]int{"GT": 0, "GQ": 1}}
+	order := make(map[string]uint64)
+	for i := 0; i < 32; i++ {
+		order[fmt.Sprintf("NA%05d", i)] = uint64(i)
+		f.Genotypes = append(f.Genotypes, []byte(fmt.Sprintf("0|1:%d", i)))
+	}
+
+	if err := f.ParseGenotypes(order); err != nil {
+		t.Fatalf("ParseGenotypes() error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sample := range order {
+				if _, err := f.SingleGenotype(sample, order); err != nil {
+					t.Errorf("SingleGenotype() error: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(f.ParsedGenotypes) != len(order) {
+		t.Fatalf("ParseGenotypes() error: cached %d genotypes, want %d", len(f.ParsedGenotypes), len(order))
+	}
+	for sample, i := range order {
+		if gq := f.ParsedGenotypes[sample].Fields["GQ"]; gq != fmt.Sprint(i) {
+			t.Errorf("SingleGenotype() error: %s has GQ %s, want %d", sample, gq, i)
+		}
+	}
+}
+
+func TestFeature_SetGenotype(t *testing.T) {
+	h := NewHeader()
+	h.FileFormat = "VCFv4.2"
+	h.Genotypes = map[string]uint64{"NA00001": 0, "NA00002": 1}
+
+	f := &Feature{Chrom: "20", Pos: 14370, Id: ".", Ref: "G", Alt: []string{"A"}, Qual: 29, QualFormat: 'f', Filter: "PASS"}
+	if err := f.SetGenotype("NA00002", &Genotype{GT: []int{1, 1}, Fields: map[string]string{"GQ": "43"}}, h.Genotypes); err != nil {
+		t.Fatalf("SetGenotype() error: %v", err)
+	}
+	if err := f.SetGenotype("NA00001", &Genotype{Fields: map[string]string{"GT": "0|1", "GQ": "48", "DP": "8"}}, h.Genotypes); err != nil {
+		t.Fatalf("SetGenotype() error: %v", err)
+	}
+	err := f.SetGenotype("NA00003", &Genotype{GT: []int{0, 0}}, h.Genotypes)
+	if want := errors.New("genotype not in vcf"); !reflect.DeepEqual(err, want) {
+		t.Errorf("SetGenotype() error: unexpected error\ngot \t%v\nwant \t%v", err, want)
+	}
+
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteAll([]*Feature{f}, h)
+	want := "##fileformat=VCFv4.2\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" +
+		"20\t14370\t.\tG\tA\t29\tPASS\t.\tGT:GQ:DP\t0|1:48:8\t1/1:43:.\n"
+	if b.String() != want {
+		t.Errorf("WriteAll() error:\ngot \t%q\nwant \t%q", b.String(), want)
+	}
+
+	gt, err := f.SingleGenotype("NA00002", h.Genotypes)
+	if err != nil {
+		t.Fatalf("SingleGenotype() error: %v", err)
+	}
+	if !reflect.DeepEqual(gt.GT, []int{1, 1}) || gt.PhasedGT || gt.Fields["GQ"] != "43" {
+		t.Errorf("SingleGenotype() error: unexpected genotype %v", gt)
+	}
+
+	// GT added to a feature that already has FORMAT keys moves them after it
+	f = &Feature{Chrom: "20", Pos: 17330, Id: ".", Ref: "T", Alt: []string{"A"}, Qual: 3, QualFormat: 'f', Filter: "q10",
+		Format: map[string]int{"DP": 0}, Genotypes: [][]byte{[]byte("3"), []byte("5")}}
+	if err := f.SetGenotype("NA00001", &Genotype{GT: []int{0, 1}, PhasedGT: true}, h.Genotypes); err != nil {
+		t.Fatalf("SetGenotype() error: %v", err)
+	}
+	b.Reset()
+	w.WriteFeature(f)
+	want = "20\t17330\t.\tT\tA\t3\tq10\t.\tGT:DP\t0|1:.\t.:5\n"
+	if b.String() != want {
+		t.Errorf("WriteFeature() error: GT added to DP\ngot \t%q\nwant \t%q", b.String(), want)
+	}
+	if gt, err := f.SingleGenotype("NA00002", h.Genotypes); err != nil || gt.Fields["DP"] != "5" || gt.GT[0] != -1 {
+		t.Errorf("SingleGenotype() error: unexpected genotype %v, %v", gt, err)
+	}
+}
+
+func TestHeader_Version(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Major int
+		Minor int
+		Error error
+	}{{
+		Name:  "VCFv4.1",
+		Input: "VCFv4.1",
+		Major: 4,
+		Minor: 1,
+	}, {
+		Name:  "VCFv4.2",
+		Input: "VCFv4.2",
+		Major: 4,
+		Minor: 2,
+	}, {
+		Name:  "VCFv4.3",
+		Input: "VCFv4.3",
+		Major: 4,
+		Minor: 3,
+	}, {
+		Name:  "Garbage",
+		Input: "VCFv4.two",
+		Error: errors.New("invalid fileformat \"VCFv4.two\""),
+	}, {
+		Name:  "NotVCF",
+		Input: "BCFv2.2",
+		Error: errors.New("invalid fileformat \"BCFv2.2\""),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			h := NewHeader()
+			h.FileFormat = tt.Input
+			major, minor, err := h.Version()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Version() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if major != tt.Major || minor != tt.Minor {
+				t.Errorf("Version() error: got %d.%d want %d.%d", major, minor, tt.Major, tt.Minor)
+			}
+		})
+	}
+}
+
+func TestFeature_GTMatrix(t *testing.T) {
+	order := map[string]uint64{"NA00001": 0, "NA00002": 1, "NA00003": 2}
+	tests := []struct {
+		Name      string
+		Genotypes []string
+		Format    map[string]int
+		Pad       bool
+		Output    [][]int
+		Error     error
+	}{{
+		Name:      "Biallelic",
+		Genotypes: []string{"0|0:48", "1|0:48", "1/1:43"},
+		Output:    [][]int{{0, 0}, {1, 0}, {1, 1}},
+	}, {
+		Name:      "Missing",
+		Genotypes: []string{"./.:48", "0/.:48", ".:43"},
+		Output:    [][]int{{-1, -1}, {0, -1}, {-1, -1}},
+	}, {
+		Name:      "RaggedError",
+		Genotypes: []string{"0|0:48", "1:48", "1/1:43"},
+		Error:     errors.New("genotype NA00002 has ploidy 1, expected 2"),
+	}, {
+		Name:      "RaggedPadded",
+		Genotypes: []string{"0|0:48", "1:48", "1/1:43"},
+		Pad:       true,
+		Output:    [][]int{{0, 0}, {1, -1}, {1, 1}},
+	}, {
+		Name:      "NoGT",
+		Genotypes: []string{"48", "48", "43"},
+		Format:    map[string]int{"GQ": 0},
+		Error:     errors.New("GT not in FORMAT"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := Feature{Format: map[string]int{"GT": 0, "GQ": 1}}
+			if tt.Format != nil {
+				f.Format = tt.Format
+			}
+			for _, gt := range tt.Genotypes {
+				f.Genotypes = append(f.Genotypes, []byte(gt))
+			}
+			out, err := f.GTMatrix(order, tt.Pad)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("GTMatrix() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("GTMatrix() error: unexpected value\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
+
+func TestHeader_SampleDefinition(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##SAMPLE=<ID=Blood,Genomes=Germline,Mixture=1.,Description=\"Patient germline genome\">\n" +
+		"##SAMPLE=<ID=TissueSample,Genomes=Germline;Tumor,Mixture=.3;.7,Description=\"Patient germline genome;Patient tumor genome\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+
+	if ids := r.Header.SampleDefinitionIDs(); !reflect.DeepEqual(ids, []string{"Blood", "TissueSample"}) {
+		t.Errorf("SampleDefinitionIDs() error: unexpected value\ngot \t%v", ids)
+	}
+	meta, ok := r.Header.SampleDefinition("TissueSample")
+	if !ok || meta.Optional["Genomes"] != "Germline;Tumor" || meta.Optional["Mixture"] != ".3;.7" {
+		t.Errorf("SampleDefinition() error: unexpected value\ngot \t%v", meta)
+	}
+	if _, ok := r.Header.SampleDefinition("Saliva"); ok {
+		t.Errorf("SampleDefinition() error: found undefined sample")
+	}
+}
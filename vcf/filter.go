@@ -0,0 +1,354 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FilterExpr streams the features of r to w, keeping those for which expr is true, like bcftools view -i.
+//
+// An expression compares INFO fields to values with ==, =, !=, <, <=, > and >=, and combines the
+// comparisons with &&, || and !, grouped with parentheses, as in "DP>10 && AF<0.01". A bare key is
+// true if the field is present, which suits flags such as "!DB". Values are compared according to
+// the ##INFO Type of the key: Integer and Float fields compare numerically, and other fields as
+// strings, which may be double quoted. Keys without a definition compare numerically if the value
+// is a number. A multi-valued field satisfies a comparison if any of its values does.
+//
+// Features that are missing a compared field, or whose value doesn't parse as its Type, are dropped
+// unless keepUnparsed is set. Such a comparison doesn't matter if the rest of the expression decides
+// the feature, so "DP>10 || DB" keeps a DB feature without DP. If w hasn't written a header yet,
+// the header of r is written.
+func FilterExpr(r *Reader, w *Writer, expr string, keepUnparsed bool) error {
+	filter, err := parseFilterExpr(expr)
+	if err != nil {
+		return err
+	}
+	if !w.Header {
+		if err := w.writeHeader(r.Header); err != nil {
+			return err
+		}
+	}
+
+	for {
+		f, err := r.Read()
+		if f != nil {
+			pass, evalErr := filter.eval(f, r.Header)
+			if (pass && evalErr == nil) || (evalErr != nil && keepUnparsed) {
+				if werr := w.writeFeature(f); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// filterNode is a node of a parsed filter expression. eval returns an error if a feature
+// lacks a field the expression compares, or the field doesn't parse.
+type filterNode interface {
+	eval(f *Feature, h *Header) (bool, error)
+}
+
+type andNode struct{ left, right filterNode }
+
+// eval is false without an error if either side is, even if the other side errors
+func (n andNode) eval(f *Feature, h *Header) (bool, error) {
+	left, leftErr := n.left.eval(f, h)
+	if !left && leftErr == nil {
+		return false, nil
+	}
+	right, rightErr := n.right.eval(f, h)
+	if !right && rightErr == nil {
+		return false, nil
+	}
+	if leftErr != nil {
+		return false, leftErr
+	}
+	return right && rightErr == nil, rightErr
+}
+
+type orNode struct{ left, right filterNode }
+
+// eval is true if either side is, even if the other side errors
+func (n orNode) eval(f *Feature, h *Header) (bool, error) {
+	left, leftErr := n.left.eval(f, h)
+	if left && leftErr == nil {
+		return true, nil
+	}
+	right, rightErr := n.right.eval(f, h)
+	if right && rightErr == nil {
+		return true, nil
+	}
+	if leftErr != nil {
+		return false, leftErr
+	}
+	return false, rightErr
+}
+
+type notNode struct{ expr filterNode }
+
+func (n notNode) eval(f *Feature, h *Header) (bool, error) {
+	ok, err := n.expr.eval(f, h)
+	return !ok && err == nil, err
+}
+
+// presentNode is a bare key, true if the INFO field is present
+type presentNode struct{ key string }
+
+func (n presentNode) eval(f *Feature, h *Header) (bool, error) {
+	_, ok := f.Info[n.key]
+	return ok, nil
+}
+
+// compareNode compares an INFO field to a value
+type compareNode struct {
+	key, op, value string
+}
+
+func (n compareNode) eval(f *Feature, h *Header) (bool, error) {
+	val, ok := f.Info[n.key]
+	if !ok || val == "." {
+		return false, fmt.Errorf("INFO %s missing", n.key)
+	}
+
+	number, typ := ".", "String"
+	if meta := findMeta(h.Infos, n.key); meta != nil {
+		number, typ = meta.Number, meta.Type
+	} else if _, err := strconv.ParseFloat(n.value, 64); err == nil {
+		typ = "Float"
+	}
+
+	typed, err := parseTyped(val, number, typ)
+	if err != nil {
+		return false, err
+	}
+	switch v := typed.(type) {
+	case int:
+		return n.compareInts([]int{v})
+	case []int:
+		return n.compareInts(v)
+	case float64:
+		return n.compareFloats([]float64{v})
+	case []float64:
+		return n.compareFloats(v)
+	case string:
+		return n.compareStrings([]string{v}), nil
+	case []string:
+		return n.compareStrings(v), nil
+	}
+	return false, fmt.Errorf("INFO %s of Type %s can't be compared", n.key, typ)
+}
+
+func (n compareNode) compareInts(vals []int) (bool, error) {
+	floats := make([]float64, 0, len(vals))
+	for _, v := range vals {
+		if v != MissingIntegerValue {
+			floats = append(floats, float64(v))
+		}
+	}
+	return n.compareFloats(floats)
+}
+
+func (n compareNode) compareFloats(vals []float64) (bool, error) {
+	want, err := strconv.ParseFloat(n.value, 64)
+	if err != nil {
+		return false, fmt.Errorf("INFO %s compared to non-numeric value %q", n.key, n.value)
+	}
+	for _, v := range vals {
+		if v != MissingFloatValue && compare(n.op, v < want, v == want) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (n compareNode) compareStrings(vals []string) bool {
+	for _, v := range vals {
+		if compare(n.op, v < n.value, v == n.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare applies a comparison operator given whether the field is less than, or equal to, the value
+func compare(op string, less, equal bool) bool {
+	switch op {
+	case "==", "=":
+		return equal
+	case "!=":
+		return !equal
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// filterParser is a recursive descent parser over the tokens of a filter expression
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseFilterExpr parses a filter expression as described by FilterExpr
+func parseFilterExpr(expr string) (filterNode, error) {
+	tokens, err := tokenizeFilterExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid filter expression: unexpected %q", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek() == "||" {
+		p.next()
+		var right filterNode
+		if right, err = p.parseAnd(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peek() == "&&" {
+		p.next()
+		var right filterNode
+		if right, err = p.parseUnary(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch tok := p.next(); {
+	case tok == "":
+		return nil, errors.New("invalid filter expression: unexpected end")
+	case tok == "!":
+		expr, err := p.parseUnary()
+		return notNode{expr}, err
+	case tok == "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("invalid filter expression: missing ')'")
+		}
+		return expr, nil
+	case isFilterOperator(tok) || strings.HasPrefix(tok, `"`):
+		return nil, fmt.Errorf("invalid filter expression: unexpected %q", tok)
+	default:
+		if !isComparison(p.peek()) {
+			return presentNode{tok}, nil
+		}
+		op := p.next()
+		value := p.next()
+		if value == "" || isFilterOperator(value) {
+			return nil, fmt.Errorf("invalid filter expression: missing value after %s%s", tok, op)
+		}
+		if strings.HasPrefix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+		return compareNode{key: tok, op: op, value: value}, nil
+	}
+}
+
+// filterOperators are the operator tokens of a filter expression, longest first
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "=", "!", "(", ")"}
+
+func isFilterOperator(tok string) bool {
+	for _, op := range filterOperators {
+		if tok == op {
+			return true
+		}
+	}
+	return false
+}
+
+func isComparison(tok string) bool {
+	switch tok {
+	case "==", "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// tokenizeFilterExpr splits a filter expression into operators, quoted strings and words
+func tokenizeFilterExpr(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		if expr[i] == ' ' || expr[i] == '\t' {
+			i++
+			continue
+		}
+		if expr[i] == '"' {
+			end := strings.IndexByte(expr[i+1:], '"')
+			if end == -1 {
+				return nil, errors.New("invalid filter expression: unterminated quoted value")
+			}
+			tokens = append(tokens, expr[i:i+end+2])
+			i += end + 2
+			continue
+		}
+		if op := operatorAt(expr[i:]); op != "" {
+			tokens = append(tokens, op)
+			i += len(op)
+			continue
+		}
+		start := i
+		for i < len(expr) && expr[i] != ' ' && expr[i] != '\t' && expr[i] != '"' && operatorAt(expr[i:]) == "" {
+			i++
+		}
+		tokens = append(tokens, expr[start:i])
+	}
+	return tokens, nil
+}
+
+// operatorAt returns the operator that s begins with, or "" if there isn't one
+func operatorAt(s string) string {
+	for _, op := range filterOperators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package vcf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterExpr(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=DP,Number=1,Type=Integer,Description=\"Total Depth\">\n" +
+		"##INFO=<ID=AF,Number=A,Type=Float,Description=\"Allele Frequency\">\n" +
+		"##INFO=<ID=DB,Number=0,Type=Flag,Description=\"dbSNP membership\">\n" +
+		"##INFO=<ID=GENE,Number=1,Type=String,Description=\"Gene\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t100\trare\tG\tA\t29\tPASS\tDP=20;AF=0.001;GENE=BRCA1\n" +
+		"20\t200\tshallow\tC\tA\t29\tPASS\tDP=5;AF=0.001;DB\n" +
+		"20\t300\tmulti\tT\tA,C\t29\tPASS\tDP=30;AF=0.5,0.005;MQ=60\n" +
+		"20\t400\tnodepth\tA\tG\t29\tPASS\tAF=0.001\n" +
+		"20\t500\tdbonly\tA\tG\t29\tPASS\tDB\n"
+
+	tests := []struct {
+		Name         string
+		Expr         string
+		KeepUnparsed bool
+		Output       []string
+		Error        error
+	}{{
+		Name:   "And",
+		Expr:   "DP>10 && AF<0.01",
+		Output: []string{"rare", "multi"},
+	}, {
+		Name:         "KeepUnparsed",
+		Expr:         "DP>10 && AF<0.01",
+		KeepUnparsed: true,
+		Output:       []string{"rare", "multi", "nodepth", "dbonly"},
+	}, {
+		Name:         "AndFalseAfterError",
+		Expr:         "DP>10 && DB",
+		KeepUnparsed: true,
+		Output:       []string{"dbonly"},
+	}, {
+		Name:   "OrNot",
+		Expr:   "!(DP>=20) || DB",
+		Output: []string{"shallow", "dbonly"},
+	}, {
+		Name:   "OrAfterError",
+		Expr:   "DP>10 || DB",
+		Output: []string{"rare", "shallow", "multi", "dbonly"},
+	}, {
+		Name:   "String",
+		Expr:   `GENE=="BRCA1"`,
+		Output: []string{"rare"},
+	}, {
+		Name:   "Undefined",
+		Expr:   "MQ>=60",
+		Output: []string{"multi"},
+	}, {
+		Name:  "MissingValue",
+		Expr:  "DP> && AF<0.01",
+		Error: errors.New(`invalid filter expression: missing value after DP>`),
+	}, {
+		Name:  "Unbalanced",
+		Expr:  "(DP>10",
+		Error: errors.New("invalid filter expression: missing ')'"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			err = FilterExpr(r, w, tt.Expr, tt.KeepUnparsed)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Fatalf("FilterExpr() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if err != nil {
+				return
+			}
+
+			out, err := NewReader(&b)
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			var ids []string
+			for {
+				f, err := out.Read()
+				if f != nil {
+					ids = append(ids, f.Id)
+				}
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("Read() error: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.Output) {
+				t.Errorf("FilterExpr() error:\ngot \t%v\nwant \t%v", ids, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFilterExpr_WriteError(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t100\tkept\tG\tA\t29\tPASS\tDP=20\n"
+	for _, header := range []bool{false, true} {
+		r, err := NewReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("NewReader() error: %v", err)
+		}
+		w, _ := NewWriter(errWriter{})
+		w.Header = header
+		if err := FilterExpr(r, w, "DP>10", false); !reflect.DeepEqual(err, errors.New("disk full")) {
+			t.Errorf("FilterExpr() error: unexpected error with header written %v\ngot \t%v\nwant \t%v", header, err, errors.New("disk full"))
+		}
+	}
+}
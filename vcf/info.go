@@ -0,0 +1,61 @@
+package vcf
+
+// AddInfo sets the INFO field key to value, adding it after the existing fields if it is new
+func (f *Feature) AddInfo(key, value string) {
+	f.setInfo(key, value)
+	delete(f.Flags, key)
+}
+
+// SetFlag sets the INFO flag key, adding it after the existing fields if it is new
+func (f *Feature) SetFlag(key string) {
+	f.setInfo(key, key)
+	f.Flags[key] = true
+}
+
+// RemoveInfo removes the INFO field or flag key, moving the fields after it up to fill its place
+func (f *Feature) RemoveInfo(key string) {
+	idx, ok := f.InfoOrder[key]
+	if !ok {
+		return
+	}
+	delete(f.Info, key)
+	delete(f.InfoOrder, key)
+	delete(f.Flags, key)
+	for k, i := range f.InfoOrder {
+		if i > idx {
+			f.InfoOrder[k] = i - 1
+		}
+	}
+}
+
+// setInfo sets Info[key] and gives key a place in InfoOrder, replacing a missing "." INFO column
+func (f *Feature) setInfo(key, value string) {
+	f.trackFlags()
+	if _, ok := f.InfoOrder["."]; ok && key != "." {
+		f.RemoveInfo(".")
+	}
+	if f.Info == nil {
+		f.Info = make(map[string]string)
+	}
+	if f.InfoOrder == nil {
+		f.InfoOrder = make(map[string]int)
+	}
+	if _, ok := f.InfoOrder[key]; !ok {
+		f.InfoOrder[key] = len(f.InfoOrder)
+	}
+	f.Info[key] = value
+}
+
+// trackFlags fills in Flags for a feature without them, such as one built by hand,
+// so that the flags it already has aren't lost once Flags is consulted by IsFlag
+func (f *Feature) trackFlags() {
+	if f.Flags != nil {
+		return
+	}
+	f.Flags = make(map[string]bool)
+	for key, val := range f.Info {
+		if key == val {
+			f.Flags[key] = true
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFeature_EditInfo(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	tests := []struct {
+		Name   string
+		Input  string
+		Edit   func(f *Feature)
+		Output string
+	}{{
+		Name:   "Add",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;DB",
+		Edit:   func(f *Feature) { f.AddInfo("DP", "14") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;DB;DP=14\n",
+	}, {
+		Name:   "Replace",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;DB",
+		Edit:   func(f *Feature) { f.AddInfo("NS", "4") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=4;DB\n",
+	}, {
+		Name:   "AddValueEqualToKey",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3",
+		Edit:   func(f *Feature) { f.AddInfo("FOO", "FOO") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;FOO=FOO\n",
+	}, {
+		Name:   "Flag",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3",
+		Edit:   func(f *Feature) { f.SetFlag("H2") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;H2\n",
+	}, {
+		Name:   "FlagMissingInfo",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\t.",
+		Edit:   func(f *Feature) { f.SetFlag("DB") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tDB\n",
+	}, {
+		Name:   "Remove",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;DB;DP=14;AF=0.5",
+		Edit:   func(f *Feature) { f.RemoveInfo("DB"); f.AddInfo("H3", "1") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3;DP=14;AF=0.5;H3=1\n",
+	}, {
+		Name:   "RemoveAll",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3",
+		Edit:   func(f *Feature) { f.RemoveInfo("NS") },
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\t.\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(header + tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			f, _ := r.Read()
+			tt.Edit(f)
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			w.WriteFeature(f)
+			if got := b.String(); got != tt.Output {
+				t.Errorf("WriteFeature() error:\ngot \n%v \nwant \n%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_SetFlagWithoutFlags(t *testing.T) {
+	f := Feature{
+		Info:      map[string]string{"NS": "3", "DB": "DB"},
+		InfoOrder: map[string]int{"NS": 0, "DB": 1},
+	}
+	f.SetFlag("H2")
+	if !f.IsFlag("DB") || !f.IsFlag("H2") || f.IsFlag("NS") {
+		t.Errorf("SetFlag() error: unexpected flags %v", f.Flags)
+	}
+}
@@ -0,0 +1,70 @@
+package vcf
+
+import (
+	"bytes"
+	"io"
+)
+
+// MissingnessStats reads the remaining features of r and returns the fraction of them in which each
+// sample's genotype is uncalled, with no called allele in GT, such as "./." or ".". Records without a
+// GT field, or whose genotype column is missing or too short to have one, count as uncalled. Partly
+// called genotypes, such as "0/.", count as called. Only the GT field of each genotype is parsed.
+// With no records, every sample has a rate of 0.
+func MissingnessStats(r *Reader) (map[string]float64, error) {
+	missing := make([]uint64, len(r.Header.Genotypes))
+	var records uint64
+	for {
+		f, err := r.Read()
+		if f != nil {
+			records++
+			gtIndex, hasGT := f.Format["GT"]
+			for i := range missing {
+				if !hasGT || i >= len(f.Genotypes) || uncalled(genotypeField(f.Genotypes[i], gtIndex)) {
+					missing[i]++
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rates := make(map[string]float64, len(r.Header.Genotypes))
+	for sample, i := range r.Header.Genotypes {
+		if records > 0 && i < uint64(len(missing)) {
+			rates[sample] = float64(missing[i]) / float64(records)
+		} else {
+			rates[sample] = 0
+		}
+	}
+	return rates, nil
+}
+
+// genotypeField returns the field at index of a raw genotype column, without splitting the rest,
+// or nil if the column has fewer fields
+func genotypeField(raw []byte, index int) []byte {
+	for ; index > 0; index-- {
+		i := bytes.IndexByte(raw, ':')
+		if i == -1 {
+			return nil
+		}
+		raw = raw[i+1:]
+	}
+	if i := bytes.IndexByte(raw, ':'); i != -1 {
+		raw = raw[:i]
+	}
+	return raw
+}
+
+// uncalled reports whether a GT field has no called allele
+func uncalled(gt []byte) bool {
+	for _, c := range gt {
+		if c != '.' && c != '/' && c != '|' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package vcf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingnessStats(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\tNA00003\tNA00004\n" +
+		"20\t100\t.\tA\tG\t.\tPASS\t.\tGT\t0/1\t./.\t1/1\t0/0\n" +
+		"20\t200\t.\tC\tT\t.\tPASS\t.\tDP:GT\t3:0|1\t4:.|.\t.:.\t5:0/.\n" +
+		"20\t300\t.\tA\tC\t.\tPASS\t.\tGT:DP\t./.:3\t./.:.\t0/1:2\t1/1:4\n" +
+		"20\t400\t.\tG\tT\t.\tPASS\t.\tDP\t3\t4\t5\t6\n" // no GT
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	got, err := MissingnessStats(r)
+	if err != nil {
+		t.Fatalf("MissingnessStats() error: %v", err)
+	}
+	want := map[string]float64{"NA00001": 0.5, "NA00002": 1, "NA00003": 0.5, "NA00004": 0.25}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingnessStats() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package vcf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Normalize left-aligns and trims the REF and ALT alleles of the feature to their most
+// parsimonious representation, updating Pos, Ref and Alt in place.
+//
+// ref is used to fetch length reference bases of chrom starting at the one-based position pos.
+// Common trailing bases are trimmed, and the alleles are shifted left one base at a time
+// until they no longer share a final base. Whenever trimming empties an allele, the preceding
+// reference base is prepended as an anchor (or the following base, if the variant is at position 1).
+// Finally, common leading bases are trimmed as long as every allele keeps at least one base.
+//
+// Features with missing, symbolic, breakend, or overlapping-deletion ALT alleles are left unchanged.
+func (f *Feature) Normalize(ref func(chrom string, pos, length uint64) ([]byte, error)) error {
+	if f.Pos == 0 || len(f.Ref) == 0 || len(f.Alt) == 0 {
+		return errors.New("feature has no position or alleles to normalize")
+	}
+
+	alleles := make([][]byte, 0, len(f.Alt)+1)
+	alleles = append(alleles, []byte(f.Ref))
+	identical := true
+	for _, alt := range f.Alt {
+		if alt == "" || alt == "." || alt == "*" || isSymbolic(alt) {
+			return nil
+		}
+		if alt != f.Ref {
+			identical = false
+		}
+		alleles = append(alleles, []byte(alt))
+	}
+	if identical { // nothing to align, and trimming would never terminate
+		return nil
+	}
+
+	pos := f.Pos
+	for changed := true; changed; {
+		changed = false
+		if sharedLast(alleles) {
+			for i := range alleles {
+				alleles[i] = alleles[i][:len(alleles[i])-1]
+			}
+			changed = true
+		}
+
+		if !anyEmpty(alleles) {
+			continue
+		}
+
+		if pos == 1 { // can't shift further left, anchor on the following base instead
+			base, err := fetchBase(ref, f.Chrom, pos+uint64(len(alleles[0])))
+			if err != nil {
+				return err
+			}
+			for i := range alleles {
+				alleles[i] = append(alleles[i], base)
+			}
+			break
+		}
+
+		pos--
+		base, err := fetchBase(ref, f.Chrom, pos)
+		if err != nil {
+			return err
+		}
+		for i := range alleles {
+			alleles[i] = append([]byte{base}, alleles[i]...)
+		}
+		changed = true
+	}
+
+	for sharedFirst(alleles) {
+		for i := range alleles {
+			alleles[i] = alleles[i][1:]
+		}
+		pos++
+	}
+
+	f.Pos = pos
+	f.Ref = string(alleles[0])
+	for i := range f.Alt {
+		f.Alt[i] = string(alleles[i+1])
+	}
+
+	return nil
+}
+
+// fetchBase returns the single reference base of chrom at the one-based position pos
+func fetchBase(ref func(chrom string, pos, length uint64) ([]byte, error), chrom string, pos uint64) (byte, error) {
+	seq, err := ref(chrom, pos, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(seq) != 1 {
+		return 0, fmt.Errorf("reference returned %d bases for %s:%d, expected 1", len(seq), chrom, pos)
+	}
+	return bytes.ToUpper(seq)[0], nil
+}
+
+// sharedLast reports whether every allele is non-empty and ends with the same base
+func sharedLast(alleles [][]byte) bool {
+	for _, a := range alleles {
+		if len(a) == 0 || a[len(a)-1] != alleles[0][len(alleles[0])-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// sharedFirst reports whether every allele has at least two bases and starts with the same base
+func sharedFirst(alleles [][]byte) bool {
+	for _, a := range alleles {
+		if len(a) < 2 || a[0] != alleles[0][0] {
+			return false
+		}
+	}
+	return true
+}
+
+// anyEmpty reports whether any allele has been trimmed to nothing
+func anyEmpty(alleles [][]byte) bool {
+	for _, a := range alleles {
+		if len(a) == 0 {
+			return true
+		}
+	}
+	return false
+}
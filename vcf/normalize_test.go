@@ -0,0 +1,69 @@
+package vcf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFeature_Normalize(t *testing.T) {
+	// G1 C2 A3 C4 A5 C6 A7 G8 T9 T10
+	seq := []byte("GCACACAGTT")
+	ref := func(chrom string, pos, length uint64) ([]byte, error) {
+		if chrom != "1" || pos < 1 || pos+length-1 > uint64(len(seq)) {
+			return nil, errors.New("out of range")
+		}
+		return seq[pos-1 : pos-1+length], nil
+	}
+
+	tests := []struct {
+		Name   string
+		Input  Feature
+		Output Feature
+		Error  error
+	}{{
+		Name:   "SNP",
+		Input:  Feature{Chrom: "1", Pos: 3, Ref: "A", Alt: []string{"T"}},
+		Output: Feature{Chrom: "1", Pos: 3, Ref: "A", Alt: []string{"T"}},
+	}, {
+		Name:   "LeftAlignDeletion",
+		Input:  Feature{Chrom: "1", Pos: 5, Ref: "ACA", Alt: []string{"A"}},
+		Output: Feature{Chrom: "1", Pos: 1, Ref: "GCA", Alt: []string{"G"}},
+	}, {
+		Name:   "LeftAlignInsertion",
+		Input:  Feature{Chrom: "1", Pos: 9, Ref: "T", Alt: []string{"TT"}},
+		Output: Feature{Chrom: "1", Pos: 8, Ref: "G", Alt: []string{"GT"}},
+	}, {
+		Name:   "TrimBothEnds",
+		Input:  Feature{Chrom: "1", Pos: 2, Ref: "CAC", Alt: []string{"CGC"}},
+		Output: Feature{Chrom: "1", Pos: 3, Ref: "A", Alt: []string{"G"}},
+	}, {
+		Name:   "MultiAllelic",
+		Input:  Feature{Chrom: "1", Pos: 7, Ref: "AG", Alt: []string{"G", "AGG"}},
+		Output: Feature{Chrom: "1", Pos: 6, Ref: "CA", Alt: []string{"C", "CAG"}},
+	}, {
+		Name:   "AnchorAtStart",
+		Input:  Feature{Chrom: "1", Pos: 1, Ref: "GC", Alt: []string{"G"}},
+		Output: Feature{Chrom: "1", Pos: 1, Ref: "GC", Alt: []string{"G"}},
+	}, {
+		Name:   "Symbolic",
+		Input:  Feature{Chrom: "1", Pos: 5, Ref: "ACA", Alt: []string{"<DEL>"}},
+		Output: Feature{Chrom: "1", Pos: 5, Ref: "ACA", Alt: []string{"<DEL>"}},
+	}, {
+		Name:   "ReferenceError",
+		Input:  Feature{Chrom: "2", Pos: 5, Ref: "ACA", Alt: []string{"A"}},
+		Output: Feature{Chrom: "2", Pos: 5, Ref: "ACA", Alt: []string{"A"}},
+		Error:  errors.New("out of range"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.Input.Normalize(ref)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Normalize() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if !reflect.DeepEqual(tt.Input, tt.Output) {
+				t.Errorf("Normalize() error: unexpected value\ngot \t%v\nwant \t%v", tt.Input, tt.Output)
+			}
+		})
+	}
+}
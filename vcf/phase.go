@@ -0,0 +1,84 @@
+package vcf
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+)
+
+// PhaseSet returns the PS (phase set) field of the genotype, which identifies the block of phased
+// genotypes it was phased with, and false if the genotype has no PS or it is missing "."
+func (g *Genotype) PhaseSet() (int, bool) {
+	val, ok := g.Fields["PS"]
+	if !ok || val == "." || val == "" {
+		return 0, false
+	}
+	ps, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, false
+	}
+	return ps, true
+}
+
+// PhaseBlock is a run of features in which a sample's genotypes are phased together, sharing a PS
+type PhaseBlock struct {
+	Sample   string
+	Chrom    string
+	PhaseSet int
+	Features []*Feature
+}
+
+// PhaseBlocks returns an iterator over the haplotype blocks of samples in the remaining features of r,
+// grouping consecutive features on a chromosome whose genotypes for a sample are phased with the same PS.
+// A nil samples groups every sample. Unphased genotypes, and phased ones without a PS, belong to no block
+// and don't break the block around them.
+//
+// Blocks are yielded as they end, when a sample's PS or the chromosome changes, and then at the end
+// of the input in sample order. A genotype that doesn't parse is yielded as an error, and grouping continues.
+// Any other error ends iteration after it is yielded.
+func PhaseBlocks(r *Reader, samples []string) iter.Seq2[*PhaseBlock, error] {
+	names := r.sampleNames(samples)
+	return func(yield func(*PhaseBlock, error) bool) {
+		open := make(map[string]*PhaseBlock, len(names))
+		for {
+			f, err := r.nextFeature()
+			if err != nil && err != io.EOF {
+				yield(nil, err)
+				return
+			}
+			if f != nil {
+				for _, sample := range names {
+					gt, gtErr := f.SingleGenotype(sample, r.Header.Genotypes)
+					if gtErr != nil {
+						if !yield(nil, fmt.Errorf("line %d: genotype %s: %v", r.LineNumber, sample, gtErr)) {
+							return
+						}
+						continue
+					}
+					ps, ok := gt.PhaseSet()
+					if !ok || !gt.PhasedGT {
+						continue
+					}
+					block := open[sample]
+					if block != nil && block.Chrom == f.Chrom && block.PhaseSet == ps {
+						block.Features = append(block.Features, f)
+						continue
+					}
+					if block != nil && !yield(block, nil) {
+						return
+					}
+					open[sample] = &PhaseBlock{Sample: sample, Chrom: f.Chrom, PhaseSet: ps, Features: []*Feature{f}}
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+		for _, sample := range names {
+			if block := open[sample]; block != nil && !yield(block, nil) {
+				return
+			}
+		}
+	}
+}
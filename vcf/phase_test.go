@@ -0,0 +1,82 @@
+package vcf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPhaseBlocks(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"##FORMAT=<ID=PS,Number=1,Type=Integer,Description=\"Phase set\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" +
+		"20\t100\t.\tA\tG\t.\tPASS\t.\tGT:PS\t0|1:100\t0/1:.\n" +
+		"20\t200\t.\tC\tT\t.\tPASS\t.\tGT:PS\t1|0:100\t0|1:200\n" +
+		"20\t300\t.\tA\tC\t.\tPASS\t.\tGT:PS\t0/1:.\t1|0:200\n" + // unphased in NA00001
+		"20\t400\t.\tG\tT\t.\tPASS\t.\tGT:PS\t1|0:100\t0|1:400\n" +
+		"20\t500\t.\tG\tT\t.\tPASS\t.\tGT:PS\t0|1:500\t1|1:400\n" +
+		"21\t500\t.\tG\tT\t.\tPASS\t.\tGT:PS\t0|1:500\t0|0:.\n"
+
+	want := []struct {
+		Sample   string
+		Chrom    string
+		PhaseSet int
+		Pos      []uint64
+	}{
+		{"NA00002", "20", 200, []uint64{200, 300}},
+		{"NA00001", "20", 100, []uint64{100, 200, 400}},
+		{"NA00001", "20", 500, []uint64{500}},
+		{"NA00001", "21", 500, []uint64{500}},
+		{"NA00002", "20", 400, []uint64{400, 500}},
+	}
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	var i int
+	for block, err := range PhaseBlocks(r, nil) {
+		if err != nil {
+			t.Fatalf("PhaseBlocks() error: %v", err)
+		}
+		if i >= len(want) {
+			t.Fatalf("PhaseBlocks() error: unexpected block %+v", block)
+		}
+		var pos []uint64
+		for _, f := range block.Features {
+			pos = append(pos, f.Pos)
+		}
+		w := want[i]
+		if block.Sample != w.Sample || block.Chrom != w.Chrom || block.PhaseSet != w.PhaseSet || !reflect.DeepEqual(pos, w.Pos) {
+			t.Errorf("PhaseBlocks() error: block %d\ngot \t%s %s:%d %v\nwant \t%s %s:%d %v", i, block.Sample, block.Chrom, block.PhaseSet, pos, w.Sample, w.Chrom, w.PhaseSet, w.Pos)
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Errorf("PhaseBlocks() error: got %d blocks, want %d", i, len(want))
+	}
+}
+
+func TestGenotype_PhaseSet(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  map[string]string
+		Output int
+		OK     bool
+	}{
+		{Name: "PhaseSet", Input: map[string]string{"GT": "0|1", "PS": "12345"}, Output: 12345, OK: true},
+		{Name: "Missing", Input: map[string]string{"GT": "0|1", "PS": "."}},
+		{Name: "Absent", Input: map[string]string{"GT": "0/1"}},
+		{Name: "Invalid", Input: map[string]string{"GT": "0|1", "PS": "block1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ps, ok := (&Genotype{Fields: tt.Input}).PhaseSet()
+			if ps != tt.Output || ok != tt.OK {
+				t.Errorf("PhaseSet() error:\ngot \t%v %v\nwant \t%v %v", ps, ok, tt.Output, tt.OK)
+			}
+		})
+	}
+}
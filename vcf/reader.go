@@ -12,22 +12,88 @@ package vcf
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"sort"
 	"strconv"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
+	"github.com/awilkey/bio-format-tools-go/util"
 )
 
+// ErrTruncated is returned by a Strict Reader when the last line of the input has no newline
+// and is missing fields, as happens when a file is cut off mid-record
+var ErrTruncated = errors.New("truncated final record")
+
 type Reader struct {
 	buf        *bufio.Reader
 	Header     *Header
 	LineNumber uint64
 	r          io.Reader
+	counter    *util.CountingReader
+
+	// Strict returns ErrTruncated for a final line that is cut off, rather than
+	// the generic error for a line with too few columns
+	Strict bool
+
+	// ExtraHeaders counts the repeated header blocks skipped by a reader from NewMultiReader
+	ExtraHeaders uint64
+	multi        bool
+
+	// a feature read ahead of the caller by Peek, returned by the next call to nextFeature
+	peeked       *Feature
+	peekedErr    error
+	hasPeeked    bool
+	peekedOffset int64
+
+	closer io.Closer
+
+	// the first error, other than io.EOF, that ended iteration with All
+	err error
+
+	// where the feature lines start, for Rewind
+	dataOffset int64
+	dataLine   uint64
 }
 
-// NewReader returns a Reader.
+// DefaultBufferSize is the read buffer size used by NewReader. It is larger than the bufio
+// default of 4096 bytes, since lines from cohorts with many samples can run to megabytes.
+const DefaultBufferSize = 64 * 1024
+
+// NewReader returns a Reader with a read buffer of DefaultBufferSize bytes.
 func NewReader(r io.Reader) (*Reader, error) {
+	return NewReaderSize(r, DefaultBufferSize)
+}
+
+// NewReaderSize returns a Reader with a read buffer of at least bufSize bytes.
+// Lines longer than the buffer are still read whole, but in fewer, larger reads.
+func NewReaderSize(r io.Reader, bufSize int) (*Reader, error) {
+	counter := util.NewCountingReader(r)
+	buf := bufio.NewReaderSize(counter, bufSize)
+	fileio.SkipBOM(buf)
+	h, LineNumber, err := readHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := counter.Count() - int64(buf.Buffered())
+	return &Reader{buf: buf, Header: h, LineNumber: LineNumber, r: r, counter: counter, dataOffset: offset, dataLine: LineNumber}, nil
+}
+
+// ReadHeader parses and returns the header of the vcf file read from r, without reading any features,
+// such as to list the samples of many files. r may be read past the #CHROM line, by up to the size of a read buffer.
+func ReadHeader(r io.Reader) (*Header, error) {
 	buf := bufio.NewReader(r)
+	fileio.SkipBOM(buf)
+	h, _, err := readHeader(buf)
+	return h, err
+}
+
+// readHeader reads the meta lines and #CHROM line of a vcf file, returning the header and the number of lines read
+func readHeader(buf *bufio.Reader) (*Header, uint64, error) {
 	var LineNumber uint64
 	var line []byte
 	var readErr error
@@ -36,7 +102,7 @@ func NewReader(r io.Reader) (*Reader, error) {
 	//read header/meta
 	for readErr == nil {
 		LineNumber++
-		line, readErr = buf.ReadBytes('\n')
+		line, readErr = util.ReadLine(buf)
 		line = bytes.TrimSpace(line)
 		line = bytes.Trim(line, "\n")
 		if LineNumber == 1 {
@@ -47,6 +113,10 @@ func NewReader(r io.Reader) (*Reader, error) {
 			} else {
 				h.FileFormat = metaFields["ID"]
 			}
+			if err := checkVersion(h); err != nil {
+				readErr = err
+				break
+			}
 		} else if bytes.HasPrefix(line, []byte("##")) { // Meta directive
 			metaFields, metaOrder, formatted, err := parseLineToMeta(line)
 			if err != nil {
@@ -63,6 +133,7 @@ func NewReader(r io.Reader) (*Reader, error) {
 				if formatted == false { // Meta directive is simple ##key=value
 					meta := SingleValMeta{FieldType: metaFields["FieldType"], Id: metaFields["ID"]}
 					h.SingleVals = append(h.SingleVals, &meta)
+					h.MetaOrder = append(h.MetaOrder, &meta)
 				} else { // Meta directive is ##key=<key=value,...>
 					var meta Meta
 					meta.FieldOrder = metaOrder[1:]
@@ -113,6 +184,7 @@ func NewReader(r io.Reader) (*Reader, error) {
 					}
 
 					h.PrintOrder = append(h.PrintOrder, &meta)
+					h.MetaOrder = append(h.MetaOrder, &meta)
 
 				}
 			}
@@ -141,22 +213,121 @@ func NewReader(r io.Reader) (*Reader, error) {
 			break
 		}
 	}
-	if !foundHeader {
+	if !foundHeader && (readErr == nil || readErr == io.EOF) { // don't mask errors from the meta lines
 		readErr = errors.New("no header line present")
 	}
 
 	// error reading header
 	if readErr != nil {
 		if len(line) == 0 && readErr == io.EOF {
-			return nil, io.EOF //EOF is expected, don't bother with error
+			return nil, LineNumber, io.EOF //EOF is expected, don't bother with error
 		} else if len(line) > 0 && readErr != io.EOF {
-			return nil, readErr //return error
+			return nil, LineNumber, readErr //return error
 		}
 	}
+	return h, LineNumber, nil
+}
+
+// Offset returns the byte offset in the input of the next line to be read
+func (gr *Reader) Offset() int64 {
+	if gr.hasPeeked {
+		return gr.peekedOffset
+	}
+	return gr.counter.Count() - int64(gr.buf.Buffered())
+}
+
+// checkVersion returns an error if the header's fileformat isn't a supported VCFv4.x version
+func checkVersion(h *Header) error {
+	major, minor, err := h.Version()
+	if err != nil {
+		return err
+	}
+	if major != 4 || minor > 4 {
+		return fmt.Errorf("unsupported vcf version %d.%d", major, minor)
+	}
+	return nil
+}
 
-	return &Reader{buf, h, LineNumber, r}, nil
+// OpenReader opens the vcf file at path and returns a Reader for it,
+// transparently decompressing gzip and bgzf files. Close must be called to close the file.
+func OpenReader(path string) (*Reader, error) {
+	rc, err := fileio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	vr, err := NewReader(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	vr.closer = rc
+	return vr, nil
 }
 
+// Close closes the file opened by OpenReader. It does nothing for readers from NewReader.
+func (gr *Reader) Close() error {
+	if gr.closer == nil {
+		return nil
+	}
+	return gr.closer.Close()
+}
+
+// Rewind seeks back to the first feature line, so that the features can be read again.
+// The underlying reader must implement io.Seeker.
+func (gr *Reader) Rewind() error {
+	seeker, ok := gr.r.(io.Seeker)
+	if !ok {
+		return errors.New("reader is not seekable")
+	}
+	if _, err := seeker.Seek(gr.dataOffset, io.SeekStart); err != nil {
+		return err
+	}
+	gr.counter.Reset(gr.dataOffset)
+	gr.buf.Reset(gr.counter)
+	gr.LineNumber = gr.dataLine
+	gr.ExtraHeaders = 0
+	gr.peeked, gr.peekedErr, gr.hasPeeked = nil, nil, false
+	gr.err = nil
+	return nil
+}
+
+// NewMultiReader returns a Reader for a stream of vcf files that have been concatenated together,
+// each with its own header. Repeated header blocks are skipped and counted in ExtraHeaders,
+// as long as their samples match those of the first header.
+func NewMultiReader(r io.Reader) (*Reader, error) {
+	vr, err := NewReader(r)
+	if vr != nil {
+		vr.multi = true
+	}
+	return vr, err
+}
+
+// checkRepeatedHeader validates a header line found after the first header block
+func (gr *Reader) checkRepeatedHeader(line []byte) error {
+	if bytes.HasPrefix(line, []byte("##")) {
+		return nil
+	}
+
+	gr.ExtraHeaders++
+	header := bytes.Split(bytes.TrimSpace(line), []byte("\t"))
+	var samples [][]byte
+	if len(header) > 9 {
+		samples = header[9:]
+	}
+	if len(samples) != len(gr.Header.Genotypes) {
+		return fmt.Errorf("repeated header on line %d has %d samples, expected %d", gr.LineNumber, len(samples), len(gr.Header.Genotypes))
+	}
+	for i, sample := range samples {
+		if col, ok := gr.Header.Genotypes[string(sample)]; !ok || col != uint64(i) {
+			return fmt.Errorf("repeated header on line %d does not match the samples of the first header", gr.LineNumber)
+		}
+	}
+	return nil
+}
+
+// parseLineToMeta splits a meta line into its fields, returning the fields, their order,
+// and whether the line is a structured ##key=<key=value,...> meta. Quoted values may contain
+// commas, equals signs, and backslash-escaped quotes.
 func parseLineToMeta(meta []byte) (map[string]string, []string, bool, error) {
 	meta = bytes.TrimLeft(bytes.TrimSpace(meta), "#")
 	line := bytes.SplitN(meta, []byte("="), 2)
@@ -166,41 +337,162 @@ func parseLineToMeta(meta []byte) (map[string]string, []string, bool, error) {
 
 	if len(line) == 1 {
 		return nil, nil, false, errors.New("invalid meta header")
+	} else if len(line[0]) == 0 {
+		return nil, nil, false, errors.New("invalid meta header: empty field type")
+	}
+
+	if !bytes.HasPrefix(line[1], []byte("<")) {
+		formatted = false
+		metaValues["FieldType"] = string(line[0])
+		metaValues["ID"] = string(line[1])
+		metaOrder = append(metaOrder, "FieldType", "ID")
 	} else {
-		if !bytes.HasPrefix(line[1], []byte("<")) {
-			formatted = false
-			metaValues["FieldType"] = string(line[0])
-			metaValues["ID"] = string(line[1])
-			metaOrder = append(metaOrder, "FieldType", "ID")
-		} else {
-			formatted = true
-			metaValues["FieldType"] = string(bytes.TrimLeft(line[0], "#"))
-			metaOrder = append(metaOrder, "FieldType")
-			fields := bytes.Split(bytes.Trim(bytes.TrimSpace(line[1]), "<>"), []byte(","))
-			for _, field := range fields {
-				info := bytes.Split(field, []byte("="))
-				if len(info) == 1 {
-					continue
-				} else {
-					metaValues[string(info[0])] = string(info[1])
-					metaOrder = append(metaOrder, string(info[0]))
-				}
+		formatted = true
+		metaValues["FieldType"] = string(line[0])
+		metaOrder = append(metaOrder, "FieldType")
+		body := bytes.TrimSpace(line[1])
+		if len(body) < 2 || body[len(body)-1] != '>' {
+			return nil, nil, false, errors.New("invalid meta header: missing closing '>'")
+		}
+		fields, err := splitMetaFields(body[1 : len(body)-1])
+		if err != nil {
+			return nil, nil, false, err
+		}
+		for _, field := range fields {
+			info := bytes.SplitN(field, []byte("="), 2)
+			if len(info) == 1 {
+				continue
+			} else if len(info[0]) == 0 {
+				return nil, nil, false, errors.New("invalid meta header: empty key")
+			}
+			if _, ok := metaValues[string(info[0])]; !ok {
+				metaOrder = append(metaOrder, string(info[0]))
 			}
+			metaValues[string(info[0])] = string(info[1])
 		}
 	}
 
 	return metaValues, metaOrder, formatted, nil
 }
 
+// splitMetaFields splits the body of a structured meta on commas that aren't within double quotes
+func splitMetaFields(body []byte) ([][]byte, error) {
+	var fields [][]byte
+	inQuotes := false
+	last := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			if inQuotes {
+				i++ // skip escaped character
+			}
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				fields = append(fields, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("invalid meta header: unterminated quoted value")
+	}
+	return append(fields, body[last:]), nil
+}
+
 // Read returns a pointer to a Feature. Input is assumed to be a properly formed gff3
 func (gr *Reader) Read() (*Feature, error) {
-	return gr.parseFeature()
+	return gr.nextFeature()
 }
 
-// ReadAll returns a slice of pointers to Features from an input of one-or-more lines
+// All returns an iterator over the remaining features, for use with range. Iteration stops at the
+// end of the input or at the first error, which is then returned by Err, as with bufio.Scanner.
+func (gr *Reader) All() iter.Seq[*Feature] {
+	return gr.all(gr.nextFeature)
+}
+
+// AllContext returns an iterator over the remaining features like All, that also stops if ctx is
+// canceled, which is checked every 64 features. Err then returns ctx.Err().
+func (gr *Reader) AllContext(ctx context.Context) iter.Seq[*Feature] {
+	return gr.all(fileio.CheckContext(ctx, gr.nextFeature))
+}
+
+// all returns an iterator over the features returned by read, recording the error that ends it for Err
+func (gr *Reader) all(read func() (*Feature, error)) iter.Seq[*Feature] {
+	return func(yield func(*Feature) bool) {
+		for gr.err == nil {
+			f, err := read()
+			if err != nil && err != io.EOF {
+				gr.err = err
+			}
+			if f != nil && !yield(f) {
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first error, other than io.EOF, that ended iteration with All
+func (gr *Reader) Err() error {
+	return gr.err
+}
+
+// IterateWithGenotypes returns an iterator over the remaining features, with the genotypes of samples
+// parsed into ParsedGenotypes before each feature is yielded. A nil samples parses every sample.
+// A feature whose genotypes don't parse is yielded along with the error, and iteration continues.
+// Any other error ends iteration after it is yielded; the end of the input ends it without one.
+func (gr *Reader) IterateWithGenotypes(samples []string) iter.Seq2[*Feature, error] {
+	names := gr.sampleNames(samples)
+	return func(yield func(*Feature, error) bool) {
+		for {
+			f, err := gr.nextFeature()
+			if err == io.EOF && f == nil {
+				return
+			}
+			if err != nil && err != io.EOF {
+				yield(f, err)
+				return
+			}
+
+			var gtErr error
+			for _, sample := range names {
+				if _, e := f.SingleGenotype(sample, gr.Header.Genotypes); e != nil {
+					gtErr = fmt.Errorf("line %d: genotype %s: %v", gr.LineNumber, sample, e)
+					break
+				}
+			}
+			if !yield(f, gtErr) || err == io.EOF {
+				return
+			}
+		}
+	}
+}
+
+// sampleNames returns samples, or every sample of the header in column order if samples is nil
+func (gr *Reader) sampleNames(samples []string) []string {
+	if samples != nil {
+		return samples
+	}
+	names := make([]string, 0, len(gr.Header.Genotypes))
+	for sample := range gr.Header.Genotypes {
+		names = append(names, sample)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return gr.Header.Genotypes[names[i]] < gr.Header.Genotypes[names[j]]
+	})
+	return names
+}
+
+// ReadAll returns a slice of pointers to Features from an input of one-or-more lines.
+// Reaching the end of the input is reported as io.EOF, so callers must treat io.EOF as success.
+// See ReadAllFeatures for a version that returns nil instead.
 func (gr *Reader) ReadAll() (features []*Feature, err error) {
 	for {
-		feature, err := gr.parseFeature()
+		feature, err := gr.nextFeature()
 		if feature != nil {
 			feat := feature
 			features = append(features, feat)
@@ -214,13 +506,85 @@ func (gr *Reader) ReadAll() (features []*Feature, err error) {
 	}
 }
 
+// ReadAllFeatures returns a slice of pointers to Features like ReadAll, but returns a nil error
+// once the input has been read to the end, like io.ReadAll. Any non-nil error is a real
+// problem with the input, and the features read before it are returned along with it.
+func (gr *Reader) ReadAllFeatures() ([]*Feature, error) {
+	features, err := gr.ReadAll()
+	if err == io.EOF {
+		err = nil
+	}
+	return features, err
+}
+
+// ReadContext returns a pointer to a Feature, or ctx.Err() if ctx has been canceled
+func (gr *Reader) ReadContext(ctx context.Context) (*Feature, error) {
+	return fileio.ReadContext(ctx, gr.nextFeature)
+}
+
+// ReadAllContext returns a slice of pointers to Features like ReadAll, but stops early
+// and returns ctx.Err() if ctx is canceled, which is checked every 64 features.
+func (gr *Reader) ReadAllContext(ctx context.Context) ([]*Feature, error) {
+	return fileio.ReadAllContext(ctx, gr.nextFeature)
+}
+
+// ReadAllLimit reads at most n features, and reports whether more features are available after them.
+// Like ReadAll, io.EOF is returned if the end of the input was reached.
+func (gr *Reader) ReadAllLimit(n int) (features []*Feature, more bool, err error) {
+	for len(features) < n {
+		feature, err := gr.nextFeature()
+		if feature != nil {
+			features = append(features, feature)
+		}
+		if err != nil {
+			return features, false, err
+		}
+	}
+
+	feature, err := gr.Peek()
+	return features, feature != nil || (err != nil && err != io.EOF), nil
+}
+
+// nextFeature returns the peeked feature if there is one, otherwise it parses the next line
+func (gr *Reader) nextFeature() (*Feature, error) {
+	if gr.hasPeeked {
+		feature, err := gr.peeked, gr.peekedErr
+		gr.peeked, gr.peekedErr, gr.hasPeeked = nil, nil, false
+		return feature, err
+	}
+	return gr.parseFeature()
+}
+
+// Peek returns the next feature without consuming it, so the following Read returns the same feature.
+// Useful for inspecting the first record, such as how many samples it has, before reading the file.
+func (gr *Reader) Peek() (*Feature, error) {
+	if !gr.hasPeeked {
+		gr.peekedOffset = gr.Offset()
+		gr.peeked, gr.peekedErr = gr.parseFeature()
+		gr.hasPeeked = true
+	}
+	return gr.peeked, gr.peekedErr
+}
+
 // parseFeature from a VCF line
 func (gr *Reader) parseFeature() (*Feature, error) {
 	var line []byte
 	var readErr error
 
 	gr.LineNumber++
-	line, readErr = gr.buf.ReadBytes('\n')
+	line, readErr = util.ReadLine(gr.buf)
+
+	// Skip over the headers of concatenated files
+	for gr.multi && bytes.HasPrefix(line, []byte{'#'}) {
+		if err := gr.checkRepeatedHeader(line); err != nil {
+			return nil, err
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+		gr.LineNumber++
+		line, readErr = util.ReadLine(gr.buf)
+	}
 
 	// Return if read error
 	if readErr != nil {
@@ -231,14 +595,26 @@ func (gr *Reader) parseFeature() (*Feature, error) {
 		}
 	}
 
+	// A final line without a newline may have been cut off mid-record
+	partial := gr.Strict && readErr == io.EOF
+
 	fields := bytes.Split(line, []byte{'\t'})
+	if partial && len(bytes.TrimSpace(fields[len(fields)-1])) == 0 {
+		return nil, ErrTruncated
+	}
 
 	if flen := len(fields); flen < 8 || flen == 9 || (flen >= 10 && flen != (len(gr.Header.Genotypes)+1+8)) { // Error if not enough fields in line
 		l := 8
 		if flen > 8 {
 			l = 8 + 1 + len(gr.Header.Genotypes)
 		}
-		er := fmt.Sprintf("too few columns in feature line: expected %d have %d", flen, l)
+		if partial {
+			return nil, ErrTruncated
+		}
+		if samples := len(gr.Header.Genotypes); samples > 0 && flen == 8+samples && !isFormat(fields[8]) {
+			return nil, fmt.Errorf("missing FORMAT column in feature line: expected %d have %d, with a genotype for each sample", l, flen)
+		}
+		er := fmt.Sprintf("too few columns in feature line: expected %d have %d", l, flen)
 		return nil, errors.New(er)
 	}
 
@@ -274,11 +650,13 @@ func (gr *Reader) parseFeature() (*Feature, error) {
 	infos := bytes.Split(fields[7], []byte{';'})
 	feat.Info = make(map[string]string, len(infos))
 	feat.InfoOrder = make(map[string]int, len(infos))
+	feat.Flags = make(map[string]bool)
 	for i := range infos {
-		bytes.TrimSpace(infos[i])
+		infos[i] = bytes.TrimSpace(infos[i])
 		curInf := bytes.Split(infos[i], []byte{'='})
 		if len(curInf) == 1 {
 			feat.Info[string(curInf[0])] = string(curInf[0])
+			feat.Flags[string(curInf[0])] = true
 		} else {
 			feat.Info[string(curInf[0])] = string(curInf[1])
 		}
@@ -297,3 +675,14 @@ func (gr *Reader) parseFeature() (*Feature, error) {
 
 	return &feat, readErr
 }
+
+// isFormat reports whether a column looks like a FORMAT column, a colon separated list of keys such as
+// GT:GQ:DP, rather than genotype values such as 0|1:48:1, which start with a digit or "."
+func isFormat(col []byte) bool {
+	for _, key := range bytes.Split(bytes.TrimSpace(col), []byte{':'}) {
+		if len(key) == 0 || !(key[0] == '_' || 'A' <= key[0] && key[0] <= 'Z' || 'a' <= key[0] && key[0] <= 'z') {
+			return false
+		}
+	}
+	return true
+}
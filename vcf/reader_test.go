@@ -1,11 +1,15 @@
 package vcf
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
 )
 
 func TestNewReader(t *testing.T) {
@@ -30,6 +34,27 @@ func TestNewReader(t *testing.T) {
 			Others:     make([]*Meta, 0),
 			PrintOrder: make([]*Meta, 0),
 			SingleVals: make([]*SingleValMeta, 0),
+			MetaOrder:  make([]fmt.Stringer, 0),
+			FileFormat: "VCFv4.2",
+			Genotypes:  make(map[string]uint64),
+		},
+	}, {
+		Name:  "ByteOrderMark",
+		Input: "\xEF\xBB\xBF##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Output: Header{
+			Metas:      make([]*Meta, 0),
+			Infos:      make([]*Meta, 0),
+			Filters:    make([]*Meta, 0),
+			Formats:    make([]*Meta, 0),
+			Alts:       make([]*Meta, 0),
+			Assemblies: make([]*Meta, 0),
+			Contigs:    make([]*Meta, 0),
+			Samples:    make([]*Meta, 0),
+			Pedigrees:  make([]*Meta, 0),
+			Others:     make([]*Meta, 0),
+			PrintOrder: make([]*Meta, 0),
+			SingleVals: make([]*SingleValMeta, 0),
+			MetaOrder:  make([]fmt.Stringer, 0),
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
@@ -54,6 +79,12 @@ func TestNewReader(t *testing.T) {
 				},
 			},
 			PrintOrder: make([]*Meta, 0),
+			MetaOrder: []fmt.Stringer{
+				&SingleValMeta{
+					FieldType: "source",
+					Id:        "myImputationProgramV3.1",
+				},
+			},
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
@@ -85,6 +116,13 @@ func TestNewReader(t *testing.T) {
 					FieldOrder: []string{"ID"},
 				},
 			},
+			MetaOrder: []fmt.Stringer{
+				&Meta{
+					FieldType:  "INFO",
+					Id:         "myImputationProgramV3.1",
+					FieldOrder: []string{"ID"},
+				},
+			},
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
@@ -116,6 +154,13 @@ func TestNewReader(t *testing.T) {
 					FieldOrder: []string{"ID"},
 				},
 			},
+			MetaOrder: []fmt.Stringer{
+				&Meta{
+					FieldType:  "INFO",
+					Id:         "myImputationProgramV3.1",
+					FieldOrder: []string{"ID"},
+				},
+			},
 			FileFormat: "VCFv4.2",
 			Genotypes:  map[string]uint64{"NA00001": 0},
 		},
@@ -131,6 +176,14 @@ func TestNewReader(t *testing.T) {
 		Name:  "ShortHeader",
 		Input: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER",
 		Error: errors.New("header has too few columns to be minimum vcf"),
+	}, {
+		Name:  "UnsupportedVersion",
+		Input: "##fileformat=VCFv5.0\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Error: errors.New("unsupported vcf version 5.0"),
+	}, {
+		Name:  "InvalidVersion",
+		Input: "##fileformat=VCFvX\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Error: errors.New("invalid fileformat \"VCFvX\""),
 	}}
 
 	for _, tt := range tests {
@@ -181,6 +234,7 @@ func TestRead(t *testing.T) {
 				"DB": 3,
 				"H2": 4,
 			},
+			Flags: map[string]bool{"DB": true, "H2": true},
 		},
 		Error: io.EOF,
 	}, {
@@ -206,6 +260,7 @@ func TestRead(t *testing.T) {
 				"DB": 3,
 				"H2": 4,
 			},
+			Flags: map[string]bool{"DB": true, "H2": true},
 		},
 	}, {
 		Name: "Genotype",
@@ -229,16 +284,60 @@ func TestRead(t *testing.T) {
 				"DB": 3,
 				"H2": 4,
 			},
+			Flags:     map[string]bool{"DB": true, "H2": true},
 			Format:    map[string]int{"DP": 2, "GQ": 1, "GT": 0, "HQ": 3},
 			Genotypes: [][]byte{{48, 124, 48, 58, 52, 56, 58, 49, 58, 53, 49, 44, 53, 49}},
 		},
 		Error: io.EOF,
+	}, {
+		Name: "SpacedInfo",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+20	14370	trs6054257	G	A	29	PASS	NS=3; DP=14; AF=0.5; DB; H2`,
+		Output: Feature{
+			Chrom:      "20",
+			Pos:        14370,
+			Id:         "trs6054257",
+			Ref:        "G",
+			Alt:        []string{"A"},
+			Qual:       29,
+			QualFormat: 'f',
+			Filter:     "PASS",
+			Info:       map[string]string{"NS": "3", "DP": "14", "AF": "0.5", "DB": "DB", "H2": "H2"},
+			InfoOrder: map[string]int{
+				"NS": 0,
+				"DP": 1,
+				"AF": 2,
+				"DB": 3,
+				"H2": 4,
+			},
+			Flags: map[string]bool{"DB": true, "H2": true},
+		},
+		Error: io.EOF,
 	}, {
 		Name: "MissingGenotypeFieldValue",
 		Input: `##fileformat=VCFv4.2
 #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001
 20	14370	trs6054257	G	A	29	PASS	NS=3;DP=14;AF=0.5;DB;H2	GT:GQ:DP:HQ`,
-		Error: errors.New("too few columns in feature line"),
+		Error: errors.New("too few columns in feature line: expected 10 have 9"),
+	}, {
+		Name: "MissingFormatColumn",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001	NA0002
+20	14370	trs6054257	G	A	29	PASS	NS=3;DP=14;AF=0.5;DB;H2	0|0:48:1:51,51	1|0:48:8:51,51`,
+		Error: errors.New("missing FORMAT column in feature line: expected 11 have 10, with a genotype for each sample"),
+	}, {
+		Name: "MissingFormatColumnOneSample",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001
+20	14370	trs6054257	G	A	29	PASS	NS=3;DP=14;AF=0.5;DB;H2	./.`,
+		Error: errors.New("missing FORMAT column in feature line: expected 10 have 9, with a genotype for each sample"),
+	}, {
+		Name: "WrongGenotypeCount",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001	NA0002	NA0003
+20	14370	trs6054257	G	A	29	PASS	NS=3;DP=14;AF=0.5;DB;H2	GT:GQ	0|0:48	1|0:48`,
+		Error: errors.New("too few columns in feature line: expected 12 have 11"),
 	}}
 
 	for _, tt := range tests {
@@ -266,3 +365,636 @@ func TestRead(t *testing.T) {
 		})
 	}
 }
+
+// endlessReader serves a header and then the same line forever, canceling its context once limit lines have been served
+type endlessReader struct {
+	header []byte
+	line   []byte
+	served int
+	limit  int
+	cancel context.CancelFunc
+}
+
+func (er *endlessReader) Read(p []byte) (int, error) {
+	er.served++
+	if er.served == 1 {
+		return copy(p, er.header), nil
+	}
+	if er.served == er.limit {
+		er.cancel()
+	}
+	return copy(p, er.line), nil
+}
+
+func TestReadAllContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	er := &endlessReader{
+		header: []byte("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"),
+		line:   []byte("20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3\n"),
+		limit:  1000,
+		cancel: cancel,
+	}
+	r, err := NewReader(er)
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	out, err := r.ReadAllContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("ReadAllContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+	if len(out) < 998 || len(out) > 1000+fileio.ContextCheckRecords {
+		t.Errorf("ReadAllContext() error: did not stop promptly, read %d features", len(out))
+	}
+
+	if _, err := r.ReadContext(ctx); err != context.Canceled {
+		t.Errorf("ReadContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+}
+
+func TestReader_AllContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	er := &endlessReader{
+		header: []byte("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"),
+		line:   []byte("20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3\n"),
+		limit:  1000,
+		cancel: cancel,
+	}
+	r, err := NewReader(er)
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	var n int
+	for range r.AllContext(ctx) {
+		n++
+	}
+	if err := r.Err(); err != context.Canceled {
+		t.Errorf("AllContext() error: unexpected error\ngot \t%v\nwant \t%v", err, context.Canceled)
+	}
+	if n < 998 || n > 1000+fileio.ContextCheckRecords {
+		t.Errorf("AllContext() error: did not stop promptly, read %d features", n)
+	}
+}
+
+func TestParseLineToMeta(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Input     string
+		Fields    map[string]string
+		Order     []string
+		Formatted bool
+		Error     error
+	}{{
+		Name:   "SingleValue",
+		Input:  "##source=myImputationProgramV3.1",
+		Fields: map[string]string{"FieldType": "source", "ID": "myImputationProgramV3.1"},
+		Order:  []string{"FieldType", "ID"},
+	}, {
+		Name:      "QuotedComma",
+		Input:     `##INFO=<ID=AF,Number=A,Type=Float,Description="Allele Frequency, for each ALT allele">`,
+		Fields:    map[string]string{"FieldType": "INFO", "ID": "AF", "Number": "A", "Type": "Float", "Description": `"Allele Frequency, for each ALT allele"`},
+		Order:     []string{"FieldType", "ID", "Number", "Type", "Description"},
+		Formatted: true,
+	}, {
+		Name:      "EscapedQuote",
+		Input:     `##FILTER=<ID=q10,Description="Quality \"below\" 10, a=b">`,
+		Fields:    map[string]string{"FieldType": "FILTER", "ID": "q10", "Description": `"Quality \"below\" 10, a=b"`},
+		Order:     []string{"FieldType", "ID", "Description"},
+		Formatted: true,
+	}, {
+		Name:      "EmptyStructured",
+		Input:     "##INFO=<>",
+		Fields:    map[string]string{"FieldType": "INFO"},
+		Order:     []string{"FieldType"},
+		Formatted: true,
+	}, {
+		Name:  "NoEquals",
+		Input: "##source",
+		Error: errors.New("invalid meta header"),
+	}, {
+		Name:  "EmptyFieldType",
+		Input: "##=<>",
+		Error: errors.New("invalid meta header: empty field type"),
+	}, {
+		Name:  "EmptyKey",
+		Input: "##key=<=>",
+		Error: errors.New("invalid meta header: empty key"),
+	}, {
+		Name:  "MissingClose",
+		Input: "##INFO=<ID=AF,Number=A",
+		Error: errors.New("invalid meta header: missing closing '>'"),
+	}, {
+		Name:  "OnlyOpen",
+		Input: "##INFO=<",
+		Error: errors.New("invalid meta header: missing closing '>'"),
+	}, {
+		Name:  "UnterminatedQuote",
+		Input: `##INFO=<ID=AF,Description="Allele>`,
+		Error: errors.New("invalid meta header: unterminated quoted value"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fields, order, formatted, err := parseLineToMeta([]byte(tt.Input))
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("parseLineToMeta() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if err == nil && (!reflect.DeepEqual(fields, tt.Fields) || !reflect.DeepEqual(order, tt.Order) || formatted != tt.Formatted) {
+				t.Errorf("parseLineToMeta() error: unexpected value\ngot \t%v %v %v\nwant \t%v %v %v", fields, order, formatted, tt.Fields, tt.Order, tt.Formatted)
+			}
+		})
+	}
+}
+
+func FuzzParseLineToMeta(f *testing.F) {
+	f.Add([]byte("##fileformat=VCFv4.2"))
+	f.Add([]byte(`##INFO=<ID=AF,Number=A,Type=Float,Description="Allele Frequency">`))
+	f.Add([]byte("##=<>"))
+	f.Add([]byte("##key=<=>"))
+	f.Add([]byte("##INFO=<"))
+	f.Fuzz(func(t *testing.T, line []byte) {
+		fields, order, _, err := parseLineToMeta(line)
+		if err != nil {
+			return
+		}
+		if fields["FieldType"] == "" {
+			t.Errorf("parseLineToMeta() error: accepted %q with an empty field type", line)
+		}
+		for _, key := range order {
+			if _, ok := fields[key]; !ok {
+				t.Errorf("parseLineToMeta() error: ordered key %q missing from fields", key)
+			}
+		}
+	})
+}
+
+func TestNewMultiReader(t *testing.T) {
+	chr20 := `##fileformat=VCFv4.2
+##contig=<ID=20>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001
+20	14370	rs6054257	G	A	29	PASS	NS=3	GT	0|0
+`
+	chr21 := `##fileformat=VCFv4.2
+##contig=<ID=21>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001
+21	1110696	rs6040355	A	G	67	PASS	NS=2	GT	1|0
+`
+	other := `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0002
+21	1110696	rs6040355	A	G	67	PASS	NS=2	GT	1|0
+`
+
+	t.Run("Matching", func(t *testing.T) {
+		r, err := NewMultiReader(strings.NewReader(chr20 + chr21))
+		if err != nil {
+			t.Fatalf("NewMultiReader() error: %v", err)
+		}
+		out, err := r.ReadAll()
+		if err != io.EOF {
+			t.Errorf("ReadAll() error: unexpected error\ngot \t%v\nwant \t%v", err, io.EOF)
+		}
+		if len(out) != 2 || out[0].Chrom != "20" || out[1].Chrom != "21" {
+			t.Errorf("ReadAll() error: unexpected features\ngot \t%v", out)
+		}
+		if r.ExtraHeaders != 1 {
+			t.Errorf("ReadAll() error: unexpected ExtraHeaders\ngot \t%v\nwant \t%v", r.ExtraHeaders, 1)
+		}
+	})
+
+	t.Run("Mismatched", func(t *testing.T) {
+		r, err := NewMultiReader(strings.NewReader(chr20 + other))
+		if err != nil {
+			t.Fatalf("NewMultiReader() error: %v", err)
+		}
+		out, err := r.ReadAll()
+		want := errors.New("repeated header on line 6 does not match the samples of the first header")
+		if !reflect.DeepEqual(err, want) {
+			t.Errorf("ReadAll() error: unexpected error\ngot \t%v\nwant \t%v", err, want)
+		}
+		if len(out) != 1 {
+			t.Errorf("ReadAll() error: unexpected features\ngot \t%v", out)
+		}
+	})
+}
+
+func TestReadAllLimit(t *testing.T) {
+	input := `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+20	14370	rs6054257	G	A	29	PASS	NS=3
+20	17330	.	T	A	3	q10	NS=3
+20	1110696	rs6040355	A	G,T	67	PASS	NS=2
+`
+	tests := []struct {
+		Name  string
+		Limit int
+		Count int
+		More  bool
+		Error error
+	}{{
+		Name:  "Smaller",
+		Limit: 2,
+		Count: 2,
+		More:  true,
+	}, {
+		Name:  "Equal",
+		Limit: 3,
+		Count: 3,
+		More:  false,
+	}, {
+		Name:  "Larger",
+		Limit: 10,
+		Count: 3,
+		More:  false,
+		Error: io.EOF,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			out, more, err := r.ReadAllLimit(tt.Limit)
+			if err != tt.Error {
+				t.Errorf("ReadAllLimit() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if len(out) != tt.Count || more != tt.More {
+				t.Errorf("ReadAllLimit() error: unexpected result\ngot \t%d %v\nwant \t%d %v", len(out), more, tt.Count, tt.More)
+			}
+
+			// features after the limit are still available to the next read
+			rest, _ := r.ReadAll()
+			if len(out)+len(rest) != 3 {
+				t.Errorf("ReadAll() error: features lost after ReadAllLimit\ngot \t%d\nwant \t%d", len(rest), 3-len(out))
+			}
+		})
+	}
+}
+
+func TestRewind(t *testing.T) {
+	input := "\xEF\xBB\xBF##fileformat=VCFv4.2\n##contig=<ID=20>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3\n" +
+		"20\t17330\t.\tT\tA\t3\tq10\tNS=3\n"
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	headerLine := r.LineNumber
+	first, _ := r.ReadAll()
+	if err := r.Rewind(); err != nil {
+		t.Fatalf("Rewind() error: %v", err)
+	}
+	if r.LineNumber != headerLine {
+		t.Errorf("Rewind() error: unexpected LineNumber\ngot \t%v\nwant \t%v", r.LineNumber, headerLine)
+	}
+	second, _ := r.ReadAll()
+	if !reflect.DeepEqual(first, second) || len(second) != 2 {
+		t.Errorf("Rewind() error: unexpected features\ngot \t%v\nwant \t%v", second, first)
+	}
+
+	r, _ = NewReader(io.MultiReader(strings.NewReader(input)))
+	if err := r.Rewind(); err == nil {
+		t.Errorf("Rewind() error: expected error for unseekable reader")
+	}
+}
+
+func TestReadAllFeatures(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	tests := []struct {
+		Name   string
+		Input  string
+		Output int
+		Error  error
+	}{{
+		Name:   "CleanEOF",
+		Input:  header + "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\n20\t17330\t.\tT\tA\t3\tq10\tNS=3\n",
+		Output: 2,
+	}, {
+		Name:   "BadLine",
+		Input:  header + "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\n20\t17330\n",
+		Output: 1,
+		Error:  errors.New("too few columns in feature line: expected 8 have 2"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			features, err := r.ReadAllFeatures()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("ReadAllFeatures() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if len(features) != tt.Output {
+				t.Errorf("ReadAllFeatures() error: got %d features want %d", len(features), tt.Output)
+			}
+		})
+	}
+}
+
+// longLineInput returns a header and a single feature line of about 5MB of genotypes
+func longLineInput() string {
+	const samples = 750000
+	var b strings.Builder
+	b.WriteString("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT")
+	for i := 0; i < samples; i++ {
+		fmt.Fprintf(&b, "\tS%d", i)
+	}
+	b.WriteString("\n20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ")
+	for i := 0; i < samples; i++ {
+		b.WriteString("\t0|1:48")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func TestNewReaderSize(t *testing.T) {
+	input := longLineInput()
+	r, err := NewReaderSize(strings.NewReader(input), 16)
+	if err != nil {
+		t.Fatalf("NewReaderSize() error: %v", err)
+	}
+	f, err := r.Read()
+	if err != nil && err != io.EOF {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(f.Genotypes) != len(r.Header.Genotypes) {
+		t.Errorf("Read() error: got %d genotypes want %d", len(f.Genotypes), len(r.Header.Genotypes))
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output map[string]uint64
+		Error  error
+	}{{
+		Name: "Samples",
+		Input: "##fileformat=VCFv4.2\n##contig=<ID=20,length=62435964>\n" +
+			"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\tNA00003\n" +
+			"20\t14370\trs6054257\tG\tA\t29\tPASS\t.\tGT\t0|0\t1|0\t1/1\n",
+		Output: map[string]uint64{"NA00001": 0, "NA00002": 1, "NA00003": 2},
+	}, {
+		Name:   "SitesOnly",
+		Input:  "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
+		Output: map[string]uint64{},
+	}, {
+		Name:  "NotVCF",
+		Input: "##gff-version 3\n",
+		Error: errors.New("fileformat is not vcf"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			h, err := ReadHeader(strings.NewReader(tt.Input))
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Fatalf("ReadHeader() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if err == nil && !reflect.DeepEqual(h.Genotypes, tt.Output) {
+				t.Errorf("ReadHeader() error: unexpected samples\ngot \t%v\nwant \t%v", h.Genotypes, tt.Output)
+			}
+		})
+	}
+}
+
+func BenchmarkReadLongLine(b *testing.B) {
+	input := longLineInput()
+	for _, size := range []int{4096, DefaultBufferSize, 1 << 20} {
+		b.Run(fmt.Sprintf("BufferSize=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				r, _ := NewReaderSize(strings.NewReader(input), size)
+				_, _ = r.Read()
+			}
+		})
+	}
+}
+
+func TestReadStrict(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\n"
+	line := "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ\t0|0:48"
+	tests := []struct {
+		Name   string
+		Input  string
+		Strict bool
+		Error  error
+	}{{
+		Name:   "Complete",
+		Input:  header + line + "\n",
+		Strict: true,
+	}, {
+		Name:   "NoFinalNewline",
+		Input:  header + line,
+		Strict: true,
+		Error:  io.EOF,
+	}, {
+		Name:   "CutMidField",
+		Input:  header + "20\t14370\t.\tG\tA\t2",
+		Strict: true,
+		Error:  ErrTruncated,
+	}, {
+		Name:   "CutAfterTab",
+		Input:  header + line[:len(line)-6],
+		Strict: true,
+		Error:  ErrTruncated,
+	}, {
+		Name:  "CutMidFieldNotStrict",
+		Input: header + "20\t14370\t.\tG\tA\t2",
+		Error: errors.New("too few columns in feature line: expected 8 have 6"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			r.Strict = tt.Strict
+			_, err = r.Read()
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Read() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
+
+func TestReader_Offset(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	line := "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\n"
+	r, err := NewReaderSize(strings.NewReader(header+line+line), 16)
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	if r.Offset() != int64(len(header)) {
+		t.Errorf("Offset() error: got %d want %d", r.Offset(), len(header))
+	}
+	_, _, _ = r.ReadAllLimit(1) // peeks at the second line
+	if want := int64(len(header) + len(line)); r.Offset() != want {
+		t.Errorf("Offset() error: got %d want %d", r.Offset(), want)
+	}
+}
+
+func TestReadLineEndings(t *testing.T) {
+	lines := []string{
+		"##fileformat=VCFv4.2",
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		"20\t14370\t.\tG\tA\t29\tPASS\tNS=3",
+		"20\t17330\t.\tT\tA\t3\tq10\tNS=3",
+	}
+	for _, ending := range []string{"\n", "\r\n", "\r"} {
+		t.Run(fmt.Sprintf("%q", ending), func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(strings.Join(lines, ending) + ending))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			features, err := r.ReadAllFeatures()
+			if err != nil {
+				t.Fatalf("ReadAllFeatures() error: %v", err)
+			}
+			if len(features) != 2 || features[1].Info["NS"] != "3" {
+				t.Errorf("ReadAllFeatures() error: got %d features", len(features))
+			}
+		})
+	}
+}
+
+func TestReadCRLFGenotypes(t *testing.T) {
+	input := "##fileformat=VCFv4.2\r\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\r\n" +
+		"20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ:HQ\t0|0:48:51,51\t1|0:48:51,52 \r\n"
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	f, err := r.Read()
+	if f == nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	gt, err := f.SingleGenotype("NA00002", r.Header.Genotypes)
+	if err != nil {
+		t.Fatalf("SingleGenotype() error: %v", err)
+	}
+	if want := map[string]string{"GT": "1|0", "GQ": "48", "HQ": "51,52"}; !reflect.DeepEqual(gt.Fields, want) {
+		t.Errorf("SingleGenotype() error: last field not clean\ngot \t%q\nwant \t%q", gt.Fields, want)
+	}
+}
+
+func TestReader_Peek(t *testing.T) {
+	input := `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA00001	NA00002
+20	14370	rs6054257	G	A	29	PASS	NS=3	GT	0|0	1|0
+20	17330	.	T	A	3	q10	NS=3	GT	0|0	0|1
+20	1110696	rs6040355	A	G,T	67	PASS	NS=2	GT	1|2	2|1
+`
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+
+	peeked, err := r.Peek()
+	if err != nil {
+		t.Fatalf("Peek() error: %v", err)
+	}
+	if again, _ := r.Peek(); again != peeked {
+		t.Errorf("Peek() error: repeated peek returned a different feature")
+	}
+	if len(peeked.Genotypes) != 2 {
+		t.Errorf("Peek() error: unexpected sample count\ngot \t%d\nwant \t%d", len(peeked.Genotypes), 2)
+	}
+
+	read, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if !reflect.DeepEqual(read, peeked) {
+		t.Errorf("Read() error: feature differs from Peek()\ngot \t%v\nwant \t%v", read, peeked)
+	}
+
+	if err := r.Rewind(); err != nil {
+		t.Fatalf("Rewind() error: %v", err)
+	}
+	_, _ = r.Peek()
+	features, err := r.ReadAll()
+	if err != io.EOF || len(features) != 3 {
+		t.Errorf("ReadAll() error: after Peek()\ngot \t%d %v\nwant \t%d %v", len(features), err, 3, io.EOF)
+	}
+}
+
+func TestReader_All(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	tests := []struct {
+		Name   string
+		Input  string
+		Output []uint64
+		Error  error
+	}{{
+		Name:   "Valid",
+		Input:  header + "20\t100\t.\tA\tG\t.\tPASS\t.\n20\t200\t.\tC\tT\t.\tPASS\t.",
+		Output: []uint64{100, 200},
+	}, {
+		Name:   "Malformed",
+		Input:  header + "20\t100\t.\tA\tG\t.\tPASS\t.\n20\t200\t.\tC\n20\t300\t.\tC\tT\t.\tPASS\t.\n",
+		Output: []uint64{100},
+		Error:  errors.New("too few columns in feature line: expected 8 have 4"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			var got []uint64
+			for f := range r.All() {
+				got = append(got, f.Pos)
+			}
+			if !reflect.DeepEqual(got, tt.Output) {
+				t.Errorf("All() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+			if err := r.Err(); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Err() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			for range r.All() {
+				t.Errorf("All() error: iteration continued after an error")
+			}
+		})
+	}
+}
+
+func TestReader_IterateWithGenotypes(t *testing.T) {
+	input := `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA00001	NA00002	NA00003
+20	14370	rs6054257	G	A	29	PASS	NS=3	GT:DP	0|0:1	1|0:8	1/1:5
+20	17330	.	T	A	3	q10	NS=3	GT:DP	0|0:3	0|1	0/0:4
+20	1110696	rs6040355	A	G,T	67	PASS	NS=2	GT:DP	1|2:6	2|1:0	2/2:4
+`
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+
+	var gts [][]int
+	var errs []error
+	for f, err := range r.IterateWithGenotypes([]string{"NA00001", "NA00002"}) {
+		errs = append(errs, err)
+		if len(f.ParsedGenotypes) > 2 {
+			t.Errorf("IterateWithGenotypes() error: unrequested genotypes parsed %v", f.ParsedGenotypes)
+		}
+		for _, sample := range []string{"NA00001", "NA00002"} {
+			if gt, ok := f.ParsedGenotypes[sample]; ok {
+				gts = append(gts, gt.GT)
+			}
+		}
+	}
+
+	wantGTs := [][]int{{0, 0}, {1, 0}, {0, 0}, {1, 2}, {2, 1}}
+	if !reflect.DeepEqual(gts, wantGTs) {
+		t.Errorf("IterateWithGenotypes() error: unexpected genotypes\ngot \t%v\nwant \t%v", gts, wantGTs)
+	}
+	wantErrs := []error{nil, errors.New("line 4: genotype NA00002: genotype has improperly formatted data"), nil}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("IterateWithGenotypes() error: unexpected errors\ngot \t%v\nwant \t%v", errs, wantErrs)
+	}
+}
@@ -0,0 +1,35 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CheckRef checks that the REF column matches the reference genome, as fetched by ref with the feature's
+// CHROM, one-based POS and the length of REF. Bases are compared case-insensitively, and an N in either REF
+// or the reference matches any base. Features with symbolic ALTs, such as <DEL>, only have their anchor base
+// in REF, so only that base is checked.
+func (f *Feature) CheckRef(ref func(chrom string, pos uint64, length int) ([]byte, error)) error {
+	if f.Ref == "" || f.Ref == "." {
+		return errors.New("feature has no REF")
+	}
+	found, err := ref(f.Chrom, f.Pos, len(f.Ref))
+	if err != nil {
+		return err
+	}
+	if len(found) != len(f.Ref) {
+		return fmt.Errorf("reference returned %d bases at %s:%d, expected %d", len(found), f.Chrom, f.Pos, len(f.Ref))
+	}
+	for i := range found {
+		if !sameBase(f.Ref[i], found[i]) {
+			return fmt.Errorf("REF %s does not match reference %s at %s:%d", f.Ref, found, f.Chrom, f.Pos)
+		}
+	}
+	return nil
+}
+
+// sameBase reports whether two bases match, ignoring case and treating N as any base
+func sameBase(a, b byte) bool {
+	a, b = a&^0x20, b&^0x20 // upper case
+	return a == b || a == 'N' || b == 'N'
+}
@@ -0,0 +1,56 @@
+package vcf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFeature_CheckRef(t *testing.T) {
+	reference := map[string]string{"20": "ACGTacgtNNAC"}
+	ref := func(chrom string, pos uint64, length int) ([]byte, error) {
+		seq, ok := reference[chrom]
+		if !ok || pos-1+uint64(length) > uint64(len(seq)) {
+			return nil, errors.New("not in reference")
+		}
+		return []byte(seq[pos-1 : pos-1+uint64(length)]), nil
+	}
+
+	tests := []struct {
+		Name  string
+		Input Feature
+		Error error
+	}{{
+		Name:  "Match",
+		Input: Feature{Chrom: "20", Pos: 2, Ref: "CGT", Alt: []string{"C"}},
+	}, {
+		Name:  "MatchCase",
+		Input: Feature{Chrom: "20", Pos: 4, Ref: "TAC", Alt: []string{"T"}},
+	}, {
+		Name:  "MatchN",
+		Input: Feature{Chrom: "20", Pos: 9, Ref: "GTA", Alt: []string{"G"}},
+	}, {
+		Name:  "Symbolic",
+		Input: Feature{Chrom: "20", Pos: 1, Ref: "A", Alt: []string{"<DEL>"}},
+	}, {
+		Name:  "Mismatch",
+		Input: Feature{Chrom: "20", Pos: 2, Ref: "CTT", Alt: []string{"C"}},
+		Error: errors.New("REF CTT does not match reference CGT at 20:2"),
+	}, {
+		Name:  "MissingRef",
+		Input: Feature{Chrom: "20", Pos: 2, Ref: ".", Alt: []string{"C"}},
+		Error: errors.New("feature has no REF"),
+	}, {
+		Name:  "Reference",
+		Input: Feature{Chrom: "21", Pos: 2, Ref: "C", Alt: []string{"T"}},
+		Error: errors.New("not in reference"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Input.CheckRef(ref); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("CheckRef() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package vcf
+
+// InRegion reports whether the feature is on chrom and overlaps any part of start-end,
+// using inclusive one-based coordinates. The feature spans its whole REF allele,
+// or up to its END INFO field for structural variants and gVCF reference blocks.
+func (f *Feature) InRegion(chrom string, start, end uint64) bool {
+	return f.Chrom == chrom && f.Pos <= end && f.EndOne() >= start
+}
@@ -0,0 +1,65 @@
+package vcf
+
+import "testing"
+
+func TestFeature_InRegion(t *testing.T) {
+	snp := Feature{Chrom: "chr3", Pos: 1000, Ref: "A"}
+	deletion := Feature{Chrom: "chr3", Pos: 995, Ref: "ACGTAC"}
+	sv := Feature{Chrom: "chr3", Pos: 2001, Ref: "N", Info: map[string]string{"END": "3000"}}
+	longSV := Feature{Chrom: "chr3", Pos: 500, Ref: "N", Info: map[string]string{"END": "5000"}}
+
+	tests := []struct {
+		Name   string
+		Input  Feature
+		Chrom  string
+		Output bool
+	}{{
+		Name:   "Inside",
+		Input:  Feature{Chrom: "chr3", Pos: 1500, Ref: "A"},
+		Chrom:  "chr3",
+		Output: true,
+	}, {
+		Name:   "StartEdge",
+		Input:  snp,
+		Chrom:  "chr3",
+		Output: true,
+	}, {
+		Name:   "EndEdge",
+		Input:  Feature{Chrom: "chr3", Pos: 2000, Ref: "AT"},
+		Chrom:  "chr3",
+		Output: true,
+	}, {
+		Name:   "DeletionOverlapsStart",
+		Input:  deletion,
+		Chrom:  "chr3",
+		Output: true,
+	}, {
+		Name:   "Before",
+		Input:  Feature{Chrom: "chr3", Pos: 998, Ref: "AT"},
+		Chrom:  "chr3",
+		Output: false,
+	}, {
+		Name:   "After",
+		Input:  sv,
+		Chrom:  "chr3",
+		Output: false,
+	}, {
+		Name:   "SpansRegion",
+		Input:  longSV,
+		Chrom:  "chr3",
+		Output: true,
+	}, {
+		Name:   "OtherChrom",
+		Input:  snp,
+		Chrom:  "chr4",
+		Output: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := tt.Input.InRegion(tt.Chrom, 1000, 2000); got != tt.Output {
+				t.Errorf("InRegion() error: got %v want %v", got, tt.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package vcf
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CompareChrom compares chromosome names in natural order, where runs of digits are compared
+// numerically so that chr2 sorts before chr10. It returns -1, 0, or 1.
+func CompareChrom(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			if c := compareDigits(a[si:i], b[sj:j]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// compareDigits compares two runs of digits numerically, without overflowing on long runs
+func compareDigits(a, b string) int {
+	for len(a) > 1 && a[0] == '0' {
+		a = a[1:]
+	}
+	for len(b) > 1 && b[0] == '0' {
+		b = b[1:]
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ContigOrder returns the IDs of the ##contig meta lines, in the order they are declared
+func (h *Header) ContigOrder() []string {
+	order := make([]string, 0, len(h.Contigs))
+	for _, contig := range h.Contigs {
+		order = append(order, contig.Id)
+	}
+	return order
+}
+
+// CompareByContig returns a comparator that orders chromosomes by their index in contigs,
+// such as from Header.ContigOrder, as htslib expects of indexed files. Chromosomes that
+// aren't in contigs sort after those that are, in natural order. It returns -1, 0, or 1.
+func CompareByContig(contigs []string) func(a, b string) int {
+	index := make(map[string]int, len(contigs))
+	for i, contig := range contigs {
+		if _, ok := index[contig]; !ok {
+			index[contig] = i
+		}
+	}
+	return func(a, b string) int {
+		ia, aok := index[a]
+		ib, bok := index[b]
+		switch {
+		case aok && bok:
+			if ia < ib {
+				return -1
+			} else if ia > ib {
+				return 1
+			}
+			return 0
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return CompareChrom(a, b)
+	}
+}
+
+// lessFeature orders features by chromosome, in natural order, then by position
+func lessFeature(a, b *Feature) bool {
+	return lessFeatureBy(a, b, CompareChrom)
+}
+
+// lessFeatureBy orders features by chromosome using compare, then by position
+func lessFeatureBy(a, b *Feature, compare func(a, b string) int) bool {
+	if c := compare(a.Chrom, b.Chrom); c != 0 {
+		return c < 0
+	}
+	return a.Pos < b.Pos
+}
+
+// SortFeatures sorts features by chromosome, in natural order, then by position.
+// The sort is stable, so features at the same position keep their order.
+func SortFeatures(features []*Feature) {
+	SortFeaturesBy(features, CompareChrom)
+}
+
+// SortFeaturesBy sorts features by chromosome using compare, such as from CompareByContig,
+// then by position. The sort is stable, so features at the same position keep their order.
+func SortFeaturesBy(features []*Feature, compare func(a, b string) int) {
+	sort.SliceStable(features, func(i, j int) bool {
+		return lessFeatureBy(features[i], features[j], compare)
+	})
+}
+
+// mergeItem is the next feature of one of the readers being merged
+type mergeItem struct {
+	feature *Feature
+	reader  int
+}
+
+// mergeHeap is a min-heap of the next feature of each reader
+type mergeHeap []mergeItem
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	if lessFeature(h[i].feature, h[j].feature) {
+		return true
+	} else if lessFeature(h[j].feature, h[i].feature) {
+		return false
+	}
+	return h[i].reader < h[j].reader
+}
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeItem)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// MergeSorted writes the features of several coordinate-sorted readers to w as a single
+// coordinate-sorted stream, ordering by chromosome in natural order and then by position.
+// Features at the same position are written in reader order. If w hasn't written a header yet,
+// the header of the first reader is written. Every reader must have the same samples, in the same
+// order, as the first.
+func MergeSorted(w *Writer, readers ...*Reader) error {
+	for i, r := range readers[min(1, len(readers)):] {
+		if !sameSamples(r.Header.Genotypes, readers[0].Header.Genotypes) {
+			return fmt.Errorf("reader %d does not match the samples of the first reader", i+1)
+		}
+	}
+	if len(readers) > 0 && !w.Header {
+		if err := w.writeHeader(readers[0].Header); err != nil {
+			return err
+		}
+	}
+
+	h := make(mergeHeap, 0, len(readers))
+	// advance reads the next feature of reader i onto the heap
+	advance := func(i int) error {
+		f, err := readers[i].Read()
+		if f != nil {
+			heap.Push(&h, mergeItem{feature: f, reader: i})
+		}
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	for i := range readers {
+		if err := advance(i); err != nil {
+			return err
+		}
+	}
+	for h.Len() > 0 {
+		item := heap.Pop(&h).(mergeItem)
+		if err := w.writeFeature(item.feature); err != nil {
+			return err
+		}
+		if err := advance(item.reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameSamples reports whether two headers' Genotypes give the same samples the same columns
+func sameSamples(a, b map[string]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for sample, col := range a {
+		if other, ok := b[sample]; !ok || other != col {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package vcf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareChrom(t *testing.T) {
+	tests := []struct {
+		A, B   string
+		Output int
+	}{
+		{"chr1", "chr1", 0},
+		{"chr2", "chr10", -1},
+		{"chr10", "chr2", 1},
+		{"2", "10", -1},
+		{"chr10", "chrX", -1},
+		{"chr1", "chr1_random", -1},
+		{"chr01", "chr1", 0},
+		{"scaffold_99", "scaffold_100", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.A+"_"+tt.B, func(t *testing.T) {
+			if got := CompareChrom(tt.A, tt.B); got != tt.Output {
+				t.Errorf("CompareChrom() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestSortFeatures(t *testing.T) {
+	features := []*Feature{
+		{Chrom: "chr10", Pos: 5},
+		{Chrom: "chr2", Pos: 300},
+		{Chrom: "chr2", Pos: 20},
+		{Chrom: "chrX", Pos: 1},
+	}
+	SortFeatures(features)
+	var got []string
+	for _, f := range features {
+		got = append(got, f.Chrom)
+	}
+	want := "chr2 chr2 chr10 chrX"
+	if strings.Join(got, " ") != want || features[0].Pos != 20 {
+		t.Errorf("SortFeatures() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+}
+
+func TestSortFeaturesBy_Contig(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##contig=<ID=chrY,length=57227415>\n" +
+		"##contig=<ID=chr2,length=242193529>\n" +
+		"##contig=<ID=chr10,length=133797422>\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	order := r.Header.ContigOrder()
+	if want := []string{"chrY", "chr2", "chr10"}; strings.Join(order, " ") != strings.Join(want, " ") {
+		t.Fatalf("ContigOrder() error:\ngot \t%v\nwant \t%v", order, want)
+	}
+
+	features := []*Feature{
+		{Chrom: "chr10", Pos: 5},
+		{Chrom: "chrUn_2", Pos: 1},
+		{Chrom: "chr2", Pos: 300},
+		{Chrom: "chrUn_1", Pos: 1},
+		{Chrom: "chr2", Pos: 20},
+		{Chrom: "chrY", Pos: 1},
+	}
+	SortFeaturesBy(features, CompareByContig(order))
+	var got []string
+	for _, f := range features {
+		got = append(got, f.Chrom)
+	}
+	want := "chrY chr2 chr2 chr10 chrUn_1 chrUn_2"
+	if strings.Join(got, " ") != want || features[1].Pos != 20 {
+		t.Errorf("SortFeaturesBy() error:\ngot \t%v\nwant \t%v", got, want)
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n"
+	a := header +
+		"chr2\t100\ta1\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr2\t300\ta2\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr10\t50\ta3\tG\tA\t29\tPASS\tNS=3\n"
+	b := header +
+		"chr2\t200\tb1\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr2\t300\tb2\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr10\t10\tb3\tG\tA\t29\tPASS\tNS=3\n" +
+		"chrX\t1\tb4\tG\tA\t29\tPASS\tNS=3"
+
+	ra, _ := NewReader(strings.NewReader(a))
+	rb, _ := NewReader(strings.NewReader(b))
+	var out bytes.Buffer
+	w, _ := NewWriter(&out)
+	if err := MergeSorted(w, ra, rb); err != nil {
+		t.Fatalf("MergeSorted() error: %v", err)
+	}
+
+	want := header +
+		"chr2\t100\ta1\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr2\t200\tb1\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr2\t300\ta2\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr2\t300\tb2\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr10\t10\tb3\tG\tA\t29\tPASS\tNS=3\n" +
+		"chr10\t50\ta3\tG\tA\t29\tPASS\tNS=3\n" +
+		"chrX\t1\tb4\tG\tA\t29\tPASS\tNS=3\n"
+	if got := out.String(); got != want {
+		t.Errorf("MergeSorted() error:\ngot \n%v \nwant \n%v", got, want)
+	}
+}
+
+func TestMergeSorted_Errors(t *testing.T) {
+	header := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n"
+	tests := []struct {
+		Name   string
+		Input  []string
+		Writer io.Writer
+		Error  error
+	}{{
+		Name:   "SampleOrder",
+		Input:  []string{header, strings.Replace(header, "NA00001\tNA00002", "NA00002\tNA00001", 1)},
+		Writer: &bytes.Buffer{},
+		Error:  errors.New("reader 1 does not match the samples of the first reader"),
+	}, {
+		Name:   "SampleCount",
+		Input:  []string{header, header, strings.Replace(header, "\tNA00002", "", 1)},
+		Writer: &bytes.Buffer{},
+		Error:  errors.New("reader 2 does not match the samples of the first reader"),
+	}, {
+		Name:   "WriteError",
+		Input:  []string{header, header + "20\t100\t.\tG\tA\t29\tPASS\tNS=3\tGT\t0|0\t0|1\n"},
+		Writer: errWriter{},
+		Error:  errors.New("disk full"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var readers []*Reader
+			for _, input := range tt.Input {
+				r, err := NewReader(strings.NewReader(input))
+				if err != nil {
+					t.Fatalf("NewReader() error: %v", err)
+				}
+				readers = append(readers, r)
+			}
+			w, _ := NewWriter(tt.Writer)
+			if err := MergeSorted(w, readers...); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("MergeSorted() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package vcf
+
+import "strings"
+
+// Stats tallies variant and genotype counts across a stream of features, like a light bcftools stats
+type Stats struct {
+	summary Summary
+}
+
+// Summary holds the counts tallied by Stats
+type Summary struct {
+	Records      uint64
+	SNPs         uint64
+	Indels       uint64
+	SVs          uint64
+	MultiAllelic uint64 // sites with more than one ALT allele
+
+	// Transitions (A<->G and C<->T) and Transversions are counted for biallelic SNPs only
+	Transitions   uint64
+	Transversions uint64
+
+	// Genotype calls, from GT. Calls with any missing allele are Missing, and haploid calls are HomRef or HomAlt.
+	HomRef  uint64
+	Het     uint64
+	HomAlt  uint64
+	Missing uint64
+}
+
+// NewStats returns an empty Stats
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Add tallies a feature and its genotypes
+func (s *Stats) Add(f *Feature) {
+	sum := &s.summary
+	sum.Records++
+	alts := f.realAlts()
+	if len(alts) > 1 {
+		sum.MultiAllelic++
+	}
+	switch {
+	case f.IsSV():
+		sum.SVs++
+	case f.IsIndel():
+		sum.Indels++
+	case f.IsSNP():
+		sum.SNPs++
+		if len(alts) == 1 {
+			switch substitution(f.Ref, alts[0]) {
+			case transition:
+				sum.Transitions++
+			case transversion:
+				sum.Transversions++
+			}
+		}
+	}
+
+	gtIndex, ok := f.Format["GT"]
+	if !ok {
+		return
+	}
+	for _, raw := range f.Genotypes {
+		fields := strings.Split(string(raw), ":")
+		if gtIndex >= len(fields) {
+			sum.Missing++
+			continue
+		}
+		alleles, _ := parseGT([]byte(fields[gtIndex]))
+		switch zygosity(alleles) {
+		case homRef:
+			sum.HomRef++
+		case het:
+			sum.Het++
+		case homAlt:
+			sum.HomAlt++
+		default:
+			sum.Missing++
+		}
+	}
+}
+
+// Summary returns the counts tallied so far
+func (s *Stats) Summary() Summary {
+	return s.summary
+}
+
+// TiTv returns the ratio of transitions to transversions, or 0 if there are no transversions
+func (s Summary) TiTv() float64 {
+	if s.Transversions == 0 {
+		return 0
+	}
+	return float64(s.Transitions) / float64(s.Transversions)
+}
+
+// HetHom returns the ratio of heterozygous to homozygous alternate calls, or 0 if there are no homozygous alternate calls
+func (s Summary) HetHom() float64 {
+	if s.HomAlt == 0 {
+		return 0
+	}
+	return float64(s.Het) / float64(s.HomAlt)
+}
+
+const (
+	notSubstitution = iota
+	transition
+	transversion
+)
+
+// substitution classifies a single base substitution as a transition, between purines (A, G)
+// or between pyrimidines (C, T), or a transversion between the two. Bases other than A, C, G
+// and T are neither.
+func substitution(ref, alt string) int {
+	purine := func(b string) (bool, bool) {
+		switch strings.ToUpper(b) {
+		case "A", "G":
+			return true, true
+		case "C", "T":
+			return false, true
+		}
+		return false, false
+	}
+	refPurine, refOK := purine(ref)
+	altPurine, altOK := purine(alt)
+	switch {
+	case !refOK || !altOK || strings.EqualFold(ref, alt):
+		return notSubstitution
+	case refPurine == altPurine:
+		return transition
+	}
+	return transversion
+}
+
+const (
+	missingCall = iota
+	homRef
+	het
+	homAlt
+)
+
+// zygosity classifies the allele indices of a GT call, with -1 for missing alleles
+func zygosity(alleles []int) int {
+	if len(alleles) == 0 {
+		return missingCall
+	}
+	for _, a := range alleles {
+		if a < 0 {
+			return missingCall
+		}
+	}
+	for _, a := range alleles[1:] {
+		if a != alleles[0] {
+			return het
+		}
+	}
+	if alleles[0] == 0 {
+		return homRef
+	}
+	return homAlt
+}
@@ -0,0 +1,53 @@
+package vcf
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\tNA00003\n" +
+		"20\t100\t.\tA\tG\t.\tPASS\t.\tGT\t0/1\t1/1\t0/0\n" + // transition
+		"20\t200\t.\tc\tT\t.\tPASS\t.\tGT:DP\t0|1:3\t0|0:4\t./.:.\n" + // transition, mixed case
+		"20\t300\t.\tA\tC\t.\tPASS\t.\tGT\t1\t0\t.\n" + // transversion, haploid
+		"20\t400\t.\tG\tT\t.\tPASS\t.\tGT\t0/1\t0/.\t1/1\n" + // transversion
+		"20\t500\t.\tA\tG,T\t.\tPASS\t.\tGT\t1/2\t0/2\t2/2\n" + // multiallelic SNP, not in Ti/Tv
+		"20\t600\t.\tAT\tA\t.\tPASS\t.\tGT\t0/1\t0/0\t0/0\n" + // deletion
+		"20\t700\t.\tT\t<DEL>\t.\tPASS\tSVTYPE=DEL\tGT\t0/1\t0/0\t0/0\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	stats := NewStats()
+	for {
+		f, err := r.Read()
+		if f != nil {
+			stats.Add(f)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error: %v", err)
+		}
+	}
+
+	want := Summary{
+		Records: 7, SNPs: 5, Indels: 1, SVs: 1, MultiAllelic: 1,
+		Transitions: 2, Transversions: 2,
+		HomRef: 7, Het: 7, HomAlt: 4, Missing: 3,
+	}
+	got := stats.Summary()
+	if got != want {
+		t.Errorf("Summary() error:\ngot \t%+v\nwant \t%+v", got, want)
+	}
+	if got.TiTv() != 1 {
+		t.Errorf("TiTv() error:\ngot \t%v\nwant \t%v", got.TiTv(), 1)
+	}
+	if got.HetHom() != 1.75 {
+		t.Errorf("HetHom() error:\ngot \t%v\nwant \t%v", got.HetHom(), 1.75)
+	}
+}
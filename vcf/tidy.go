@@ -0,0 +1,81 @@
+package vcf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteTidy streams the features of r to w as a long-format tab-separated table,
+// with one row per (variant, sample) pair.
+//
+// Columns are chrom, pos, id, ref, alt and sample, followed by the requested INFO
+// keys and then the requested FORMAT keys. Missing values are written as ".".
+// A vcf without genotypes yields one row per variant with a sample of ".".
+func WriteTidy(w io.Writer, r *Reader, infoKeys, formatKeys []string) error {
+	cols := append([]string{"chrom", "pos", "id", "ref", "alt", "sample"}, infoKeys...)
+	cols = append(cols, formatKeys...)
+	if _, err := fmt.Fprintln(w, strings.Join(cols, "\t")); err != nil {
+		return err
+	}
+
+	samples := make([]string, len(r.Header.Genotypes))
+	for key, val := range r.Header.Genotypes {
+		samples[val] = key
+	}
+
+	row := make([]string, len(cols))
+	for {
+		f, err := r.Read()
+		if f != nil {
+			row[0] = f.Chrom
+			row[1] = strconv.FormatUint(f.Pos, 10)
+			row[2] = f.Id
+			row[3] = f.Ref
+			row[4] = strings.Join(f.Alt, ",")
+			for i, key := range infoKeys {
+				row[6+i] = tidyValue(f.Info[key])
+			}
+
+			fmtCols := row[6+len(infoKeys):]
+			if len(samples) == 0 {
+				row[5] = "."
+				for i := range fmtCols {
+					fmtCols[i] = "."
+				}
+				if _, werr := fmt.Fprintln(w, strings.Join(row, "\t")); werr != nil {
+					return werr
+				}
+			}
+			for _, sample := range samples {
+				row[5] = sample
+				gt, gtErr := f.SingleGenotype(sample, r.Header.Genotypes)
+				for i, key := range formatKeys {
+					if gtErr != nil {
+						fmtCols[i] = "."
+					} else {
+						fmtCols[i] = tidyValue(gt.Fields[key])
+					}
+				}
+				if _, werr := fmt.Fprintln(w, strings.Join(row, "\t")); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// tidyValue replaces empty values with the vcf missing value "."
+func tidyValue(val string) string {
+	if val == "" {
+		return "."
+	}
+	return val
+}
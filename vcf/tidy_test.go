@@ -0,0 +1,54 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTidy(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Input      string
+		InfoKeys   []string
+		FormatKeys []string
+		Output     string
+	}{{
+		Name: "NoGenotypes",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+20	14370	trs6054257	G	A	29	PASS	NS=3;DP=14;AF=0.5;DB;H2`,
+		InfoKeys:   []string{"DP", "AA"},
+		FormatKeys: []string{"GT"},
+		Output:     "chrom\tpos\tid\tref\talt\tsample\tDP\tAA\tGT\n20\t14370\ttrs6054257\tG\tA\t.\t14\t.\t.\n",
+	}, {
+		Name: "Genotypes",
+		Input: `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA0001	NA0002
+20	14370	trs6054257	G	A,T	29	PASS	NS=3;DP=14	GT:GQ	0|0:48	1/2:43
+20	14371	trs6054258	A	G	29	PASS	NS=3;DP=11	GT:GQ	0|1:12	1/1:9`,
+		InfoKeys:   []string{"DP"},
+		FormatKeys: []string{"GT", "GQ", "HQ"},
+		Output: "chrom\tpos\tid\tref\talt\tsample\tDP\tGT\tGQ\tHQ\n" +
+			"20\t14370\ttrs6054257\tG\tA,T\tNA0001\t14\t0|0\t48\t.\n" +
+			"20\t14370\ttrs6054257\tG\tA,T\tNA0002\t14\t1/2\t43\t.\n" +
+			"20\t14371\ttrs6054258\tA\tG\tNA0001\t11\t0|1\t12\t.\n" +
+			"20\t14371\ttrs6054258\tA\tG\tNA0002\t11\t1/1\t9\t.\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tt.Input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			var b bytes.Buffer
+			if err := WriteTidy(&b, r, tt.InfoKeys, tt.FormatKeys); err != nil {
+				t.Errorf("WriteTidy() error: unexpected error %v", err)
+			}
+			if got := b.String(); got != tt.Output {
+				t.Errorf("WriteTidy() error:\ngot \n%v want \n%v", got, tt.Output)
+			}
+		})
+	}
+}
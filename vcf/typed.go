@@ -0,0 +1,129 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Integer values may be undefined ".", which is given as MissingIntegerValue when parsed
+const MissingIntegerValue = math.MinInt32
+
+// Float values may be undefined ".", which is given as MissingFloatValue when parsed
+const MissingFloatValue = math.MaxFloat64
+
+// TypedField returns the value of the genotype field key, converted according to its ##FORMAT
+// definition in h.
+//
+// Integer values are returned as int, Float as float64, and String or Character as string.
+// Fields with a Number other than 1 are returned as a slice of the type, split on commas.
+// Flag fields are returned as true.
+func (g *Genotype) TypedField(key string, h *Header) (interface{}, error) {
+	val, ok := g.Fields[key]
+	if !ok {
+		return nil, errors.New("field not in genotype")
+	}
+	meta := findMeta(h.Formats, key)
+	if meta == nil {
+		return nil, fmt.Errorf("FORMAT %s not defined in header", key)
+	}
+	return parseTyped(val, meta.Number, meta.Type)
+}
+
+// InfoPerAllele splits a per-allele INFO field into one value per allele, checking its arity
+// against the ##INFO definition in h. Number=A fields yield a value for each ALT allele,
+// and Number=R fields yield a value for the REF allele followed by each ALT allele.
+func (f *Feature) InfoPerAllele(key string, h *Header) ([]string, error) {
+	val, ok := f.Info[key]
+	if !ok {
+		return nil, errors.New("field not in INFO")
+	}
+	meta := findMeta(h.Infos, key)
+	if meta == nil {
+		return nil, fmt.Errorf("INFO %s not defined in header", key)
+	}
+
+	var expected int
+	switch meta.Number {
+	case "A":
+		expected = len(f.Alt)
+	case "R":
+		expected = len(f.Alt) + 1
+	default:
+		return nil, fmt.Errorf("INFO %s is not per-allele (Number=%s)", key, meta.Number)
+	}
+
+	vals := strings.Split(val, ",")
+	if len(vals) != expected {
+		return nil, fmt.Errorf("INFO %s has %d values, expected %d for Number=%s", key, len(vals), expected, meta.Number)
+	}
+	return vals, nil
+}
+
+// findMeta returns the meta with the given ID, or nil if there isn't one
+func findMeta(metas []*Meta, id string) *Meta {
+	for _, meta := range metas {
+		if meta.Id == id {
+			return meta
+		}
+	}
+	return nil
+}
+
+// parseTyped converts val according to a meta Number and Type
+func parseTyped(val, number, typ string) (interface{}, error) {
+	if typ == "Flag" {
+		return true, nil
+	}
+
+	vals := []string{val}
+	if number != "1" {
+		vals = strings.Split(val, ",")
+	}
+
+	switch typ {
+	case "Integer":
+		ints := make([]int, len(vals))
+		for i, v := range vals {
+			if v == "." {
+				ints[i] = MissingIntegerValue
+				continue
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Integer value %q", v)
+			}
+			ints[i] = n
+		}
+		if number == "1" {
+			return ints[0], nil
+		}
+		return ints, nil
+	case "Float":
+		floats := make([]float64, len(vals))
+		for i, v := range vals {
+			if v == "." {
+				floats[i] = MissingFloatValue
+				continue
+			}
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Float value %q", v)
+			}
+			floats[i] = n
+		}
+		if number == "1" {
+			return floats[0], nil
+		}
+		return floats, nil
+	case "String", "Character":
+		if number == "1" {
+			return vals[0], nil
+		}
+		return vals, nil
+	default:
+		return nil, fmt.Errorf("unknown Type %q", typ)
+	}
+}
@@ -0,0 +1,136 @@
+package vcf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenotype_TypedField(t *testing.T) {
+	h := NewHeader()
+	h.Formats = []*Meta{
+		{FieldType: "FORMAT", Id: "GT", Number: "1", Type: "String"},
+		{FieldType: "FORMAT", Id: "GQ", Number: "1", Type: "Integer"},
+		{FieldType: "FORMAT", Id: "PL", Number: "G", Type: "Integer"},
+		{FieldType: "FORMAT", Id: "GL", Number: "G", Type: "Float"},
+		{FieldType: "FORMAT", Id: "XX", Number: "1", Type: "Unknown"},
+	}
+	gt := Genotype{
+		Id:     "NA0001",
+		GT:     []int{0, 1},
+		Fields: map[string]string{"GT": "0/1", "GQ": "48", "PL": "51,0,.", "GL": "-0.1,-2.5,-9", "DP": "1", "XX": "1"},
+	}
+
+	tests := []struct {
+		Name   string
+		Key    string
+		Output interface{}
+		Error  error
+	}{{
+		Name:   "ScalarString",
+		Key:    "GT",
+		Output: "0/1",
+	}, {
+		Name:   "ScalarInteger",
+		Key:    "GQ",
+		Output: 48,
+	}, {
+		Name:   "GenotypeInteger",
+		Key:    "PL",
+		Output: []int{51, 0, MissingIntegerValue},
+	}, {
+		Name:   "GenotypeFloat",
+		Key:    "GL",
+		Output: []float64{-0.1, -2.5, -9},
+	}, {
+		Name:  "NotInHeader",
+		Key:   "DP",
+		Error: errors.New("FORMAT DP not defined in header"),
+	}, {
+		Name:  "NotInGenotype",
+		Key:   "HQ",
+		Error: errors.New("field not in genotype"),
+	}, {
+		Name:  "UnknownType",
+		Key:   "XX",
+		Error: errors.New("unknown Type \"Unknown\""),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := gt.TypedField(tt.Key, h)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("TypedField() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("TypedField() error: unexpected value\ngot \t%#v\nwant \t%#v", out, tt.Output)
+			}
+		})
+	}
+}
+
+func TestFeature_InfoPerAllele(t *testing.T) {
+	h := NewHeader()
+	h.AddInfo("AF", "A", "Float", "Allele Frequency")
+	h.AddInfo("AD", "R", "Integer", "Allele Depth")
+	h.AddInfo("DP", "1", "Integer", "Total Depth")
+	f := Feature{
+		Ref:  "A",
+		Alt:  []string{"C", "G", "T"},
+		Info: map[string]string{"AF": "0.1,0.2,0.3", "AD": "10,1,2,3", "DP": "16", "BAD": "1"},
+	}
+
+	tests := []struct {
+		Name   string
+		Key    string
+		Alt    []string
+		Output []string
+		Error  error
+	}{{
+		Name:   "NumberA",
+		Key:    "AF",
+		Alt:    []string{"C", "G", "T"},
+		Output: []string{"0.1", "0.2", "0.3"},
+	}, {
+		Name:   "NumberR",
+		Key:    "AD",
+		Alt:    []string{"C", "G", "T"},
+		Output: []string{"10", "1", "2", "3"},
+	}, {
+		Name:  "NumberAMismatch",
+		Key:   "AF",
+		Alt:   []string{"C", "G"},
+		Error: errors.New("INFO AF has 3 values, expected 2 for Number=A"),
+	}, {
+		Name:  "NumberRMismatch",
+		Key:   "AD",
+		Alt:   []string{"C", "G", "T", "<DEL>"},
+		Error: errors.New("INFO AD has 4 values, expected 5 for Number=R"),
+	}, {
+		Name:  "NotPerAllele",
+		Key:   "DP",
+		Alt:   []string{"C", "G", "T"},
+		Error: errors.New("INFO DP is not per-allele (Number=1)"),
+	}, {
+		Name:  "NotInHeader",
+		Key:   "BAD",
+		Alt:   []string{"C", "G", "T"},
+		Error: errors.New("INFO BAD not defined in header"),
+	}, {
+		Name:  "NotInFeature",
+		Key:   "AC",
+		Alt:   []string{"C", "G", "T"},
+		Error: errors.New("field not in INFO"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			f.Alt = tt.Alt
+			out, err := f.InfoPerAllele(tt.Key, h)
+			if !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("InfoPerAllele() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("InfoPerAllele() error: unexpected value\ngot \t%v\nwant \t%v", out, tt.Output)
+			}
+		})
+	}
+}
@@ -4,86 +4,254 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/awilkey/bio-format-tools-go/internal/fileio"
 )
 
 // Writer allows writing gff3 files
 type Writer struct {
 	io.Writer
 	Header bool
+	closer io.Closer
+
+	// SortedInfo writes INFO keys in the order of their ##INFO lines in the written header, followed by
+	// any undeclared keys in alphabetical order, rather than in the order they were read. Keys with empty
+	// values are left out, so that output doesn't depend on how the features were built.
+	SortedInfo bool
+	header     *Header
+
+	// CRLF terminates lines with "\r\n", as expected by some Windows tools, rather than "\n"
+	CRLF bool
 }
 
 // NewWriter returns a writer after appending gff header
 func NewWriter(w io.Writer) (*Writer, error) {
-	return &Writer{w, false}, nil
+	return &Writer{Writer: w}, nil
 }
 
-func (w *Writer) WriteHeader(h Header) {
-
-	_, _ = fmt.Fprintf(w, "##fileformat=%s\n", h.FileFormat)
-	for _, val := range h.SingleVals { // Print all ##key=value lines
-		_, _ = fmt.Fprintf(w, "%s\n", val)
+// OpenWriter creates the file at path and returns a writer for it.
+// Files ending in .gz are gzip compressed, and files ending in .bgz are bgzf compressed.
+// Close must be called to flush and close the file.
+func OpenWriter(path string) (*Writer, error) {
+	wc, err := fileio.Create(path)
+	if err != nil {
+		return nil, err
 	}
-	for _, val := range h.PrintOrder { // Print all ##key=<key=value> lines
-		_, _ = fmt.Fprintf(w, "%s\n", val)
+	w, err := NewWriter(wc)
+	if err != nil {
+		_ = wc.Close()
+		return nil, err
 	}
+	w.closer = wc
+	return w, nil
+}
 
-	_, _ = fmt.Fprintf(w, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO")
-	if len(h.Genotypes) > 0 {
-		gt := make([]string, len(h.Genotypes))
-		for key, val := range h.Genotypes {
-			gt[val] = key
-		}
-		_, _ = fmt.Fprintf(w, "\tFORMAT\t%s", strings.Join(gt, "\t"))
+// Close flushes and closes the file created by OpenWriter. It does nothing for writers from NewWriter.
+func (w *Writer) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
+
+// WriteHeader writes the meta lines and the #CHROM header line, each terminated by a newline
+func (w *Writer) WriteHeader(h Header) {
+	_ = w.writeHeader(&h)
+}
+
+// writeHeader writes the header, returning any error from the underlying writer
+func (w *Writer) writeHeader(h *Header) error {
+	w.Header = true
+	w.header = h
+	_, err := w.Write(w.terminate([]byte(h.String())))
+	return err
+}
+
+// terminate swaps the "\n" ending each line of b for "\r\n" if CRLF is set.
+// Header and feature lines can't contain newlines of their own.
+func (w *Writer) terminate(b []byte) []byte {
+	if !w.CRLF {
+		return b
 	}
+	return bytes.ReplaceAll(b, []byte{'\n'}, []byte{'\r', '\n'})
+}
+
+// WriteSitesOnlyHeader writes the header like WriteHeader, but without the FORMAT and sample columns
+// of the #CHROM line, for use with WriteSitesOnly. Meta lines, ##FORMAT included, are kept.
+func (w *Writer) WriteSitesOnlyHeader(h Header) {
+	h.Genotypes = nil
+	w.WriteHeader(h)
+}
+
+// WriteSitesOnly writes the eight fixed columns of a feature line, dropping FORMAT and any genotypes
+// the feature has, for sharing allele frequencies without per-sample data. The feature isn't changed.
+func (w *Writer) WriteSitesOnly(f *Feature) {
+	sites := *f
+	sites.Format, sites.Genotypes, sites.ParsedGenotypes = nil, nil, nil
+	w.WriteFeature(&sites)
 }
 
-// WriteFeature writes a single gff feature line
+// WriteFeature writes a single vcf feature line, terminated by a newline.
+// Genotypes edited with Genotype.SetField are written as edited, without changing the feature.
 func (w *Writer) WriteFeature(f *Feature, h ...*Header) {
 	// Write header if provided and it hasn't been printed already
-	if h != nil {
+	if len(h) > 0 && h[0] != nil {
 		if w.Header == false {
 			w.WriteHeader(*h[0])
 		}
 	}
 
-	//Prep QUAL and INFO fields for pretty printing
-	var qual string
-	if f.Qual == MissingQualField {
-		qual = "."
+	_ = w.writeFeature(f)
+}
+
+// writeFeature writes a single vcf feature line, returning any error from the underlying writer
+func (w *Writer) writeFeature(f *Feature) error {
+	_, err := w.Write(w.line(f))
+	return err
+}
+
+// line returns the feature line as configured by SortedInfo and CRLF
+func (w *Writer) line(f *Feature) []byte {
+	var info []string
+	if w.SortedInfo {
+		info = w.sortedInfo(f)
 	} else {
-		qual = strconv.FormatFloat(f.Qual, f.QualFormat, -1, 64)
+		info = f.infoFields()
 	}
+	return w.terminate(f.appendLine(nil, info))
+}
+
+// WriteTo writes the feature line to w, terminated by a newline, implementing io.WriterTo.
+// The line is the same as written by a Writer with the default options, INFO fields in InfoOrder
+// and "\n" line endings, and errors writing it are returned.
+func (f *Feature) WriteTo(w io.Writer) (int64, error) {
+	var defaults Writer
+	n, err := w.Write(defaults.line(f))
+	return int64(n), err
+}
+
+// infoFields returns the INFO fields of f in InfoOrder, with flags given by their key alone
+func (f *Feature) infoFields() []string {
 	info := make([]string, len(f.Info))
 	for key, i := range f.InfoOrder {
 		val := f.Info[key]
-		if key != val {
+		if !f.IsFlag(key) {
 			info[i] = fmt.Sprintf("%s=%s", key, val)
 		} else {
 			info[i] = fmt.Sprintf("%s", key)
 		}
 	}
+	return info
+}
+
+// appendLine appends the feature line, with the given INFO fields, to b.
+// Genotypes edited with Genotype.SetField are written as SyncGenotypes would leave them, but f isn't changed.
+func (f *Feature) appendLine(b []byte, info []string) []byte {
+	//Prep QUAL and INFO fields for pretty printing
+	var qual string
+	if f.Qual == MissingQualField {
+		qual = "."
+	} else {
+		qual = strconv.FormatFloat(f.Qual, f.QualFormat, -1, 64)
+	}
+	infoCol := strings.Join(info, ";")
+	if infoCol == "" {
+		infoCol = "."
+	}
 	// print required lines
-	_, _ = fmt.Fprintf(w, "\n%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s", f.Chrom, f.Pos, f.Id, f.Ref, strings.Join(f.Alt, ","), qual, f.Filter, strings.Join(info, ";"))
+	b = append(b, fmt.Sprintf("%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s", f.Chrom, f.Pos, f.Id, f.Ref, strings.Join(f.Alt, ","), qual, f.Filter, infoCol)...)
 
 	// print genotype values
-	if len(f.Genotypes) > 0 {
-		form := make([]string, len(f.Format))
-		for key, val := range f.Format {
+	format, genotypes, _ := f.synced()
+	if len(genotypes) > 0 {
+		form := make([]string, len(format))
+		for key, val := range format {
 			form[val] = key
 		}
-		_, _ = fmt.Fprintf(w, "\t%s\t%s", strings.Join(form, ":"), bytes.Join(f.Genotypes, []byte{'\t'}))
+		b = append(b, fmt.Sprintf("\t%s\t%s", strings.Join(form, ":"), bytes.Join(genotypes, []byte{'\t'}))...)
 	}
+	return append(b, '\n')
 }
 
-// WriteAll writes all features in a slice
+// sortedInfo returns the INFO fields of f in header order, then alphabetical order, as described by SortedInfo
+func (w *Writer) sortedInfo(f *Feature) []string {
+	rank := make(map[string]int)
+	if w.header != nil {
+		for i, meta := range w.header.Infos {
+			if _, ok := rank[meta.Id]; !ok {
+				rank[meta.Id] = i
+			}
+		}
+	}
+	keys := make([]string, 0, len(f.Info))
+	for key, val := range f.Info {
+		if key != "." && val != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, iok := rank[keys[i]]
+		rj, jok := rank[keys[j]]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		}
+		return keys[i] < keys[j]
+	})
+
+	info := make([]string, len(keys))
+	for i, key := range keys {
+		if f.IsFlag(key) {
+			info[i] = key
+		} else {
+			info[i] = key + "=" + f.Info[key]
+		}
+	}
+	return info
+}
+
+// WriteAll writes all features in a slice, after the header if one is provided.
+// A header with no features is still a valid vcf file.
 func (w *Writer) WriteAll(f []*Feature, h ...*Header) {
-	if h[0] != nil {
+	if len(h) > 0 && h[0] != nil {
 		w.WriteHeader(*h[0])
 	}
 
 	for _, line := range f {
-		w.WriteFeature(line, nil)
+		w.WriteFeature(line)
+	}
+}
+
+// Copy streams every feature of r to w, after the header h, without holding the file in memory.
+// If h is nil the header of r is used, and if w has already written a header none is written.
+// Unlike WriteAll, errors reading r or writing w are returned, and copying stops at the first one.
+func Copy(w *Writer, r *Reader, h *Header) error {
+	if h == nil {
+		h = r.Header
+	}
+	if !w.Header && h != nil {
+		if err := w.writeHeader(h); err != nil {
+			return err
+		}
+	}
+
+	for {
+		f, err := r.Read()
+		if f != nil {
+			if werr := w.writeFeature(f); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 	}
 }
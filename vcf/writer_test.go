@@ -2,6 +2,13 @@ package vcf
 
 import (
 	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -28,7 +35,7 @@ func TestWriteHeader(t *testing.T) {
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
-		Output: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Output: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
 	}, {
 		Name: "SingleValueMeta",
 		Input: Header{
@@ -52,7 +59,7 @@ func TestWriteHeader(t *testing.T) {
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
-		Output: "##fileformat=VCFv4.2\n##source=myImputationProgramV3.1\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Output: "##fileformat=VCFv4.2\n##source=myImputationProgramV3.1\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
 	}, {
 		Name: "SingleComplexMeta",
 		Input: Header{
@@ -88,7 +95,7 @@ func TestWriteHeader(t *testing.T) {
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
-		Output: "##fileformat=VCFv4.2\n##source=myImputationProgramV3.1\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Output: "##fileformat=VCFv4.2\n##source=myImputationProgramV3.1\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
 	}, {
 		Name: "SingleAndMeta",
 		Input: Header{
@@ -119,10 +126,10 @@ func TestWriteHeader(t *testing.T) {
 			FileFormat: "VCFv4.2",
 			Genotypes:  make(map[string]uint64),
 		},
-		Output: "##fileformat=VCFv4.2\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+		Output: "##fileformat=VCFv4.2\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
 	}, {
 		Name:   "MetaAndGenotype",
-		Output: "##fileformat=VCFv4.2\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001",
+		Output: "##fileformat=VCFv4.2\n##INFO=<ID=myImputationProgramV3.1>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\n",
 		Input: Header{
 			Metas: make([]*Meta, 0),
 			Infos: []*Meta{
@@ -191,7 +198,7 @@ func TestWriteFeature(t *testing.T) {
 				"H2": 4,
 			},
 		},
-		Output: "\n20\t14370\ttrs6054257\tG\tA\t29\tPASS\tNS=3;DP=14;AF=0.5;DB;H2",
+		Output: "20\t14370\ttrs6054257\tG\tA\t29\tPASS\tNS=3;DP=14;AF=0.5;DB;H2\n",
 	}}
 
 	for _, tt := range tests {
@@ -206,3 +213,497 @@ func TestWriteFeature(t *testing.T) {
 		})
 	}
 }
+
+// errWriter fails every write
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestFeature_WriteTo(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n"
+	line := "20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3;DB\tGT:DP\t0|0:1\t1|0:8\n"
+	r, err := NewReader(strings.NewReader(input + line))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	f, _ := r.Read()
+
+	var b bytes.Buffer
+	n, err := f.WriteTo(&b)
+	if err != nil || n != int64(len(line)) || b.String() != line {
+		t.Errorf("WriteTo() error:\ngot \t%d %v %q\nwant \t%d %v %q", n, err, b.String(), len(line), nil, line)
+	}
+
+	var wb bytes.Buffer
+	w, _ := NewWriter(&wb)
+	w.WriteFeature(f)
+	if wb.String() != b.String() {
+		t.Errorf("WriteTo() error: differs from WriteFeature()\ngot \t%q\nwant \t%q", b.String(), wb.String())
+	}
+
+	if _, err := f.WriteTo(errWriter{}); !reflect.DeepEqual(err, errors.New("disk full")) {
+		t.Errorf("WriteTo() error: write error not returned\ngot \t%v", err)
+	}
+}
+
+func TestWriteSitesOnly(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3;AF=0.5\tGT\t0|0\t1|0\n"
+	want := "##fileformat=VCFv4.2\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3;AF=0.5\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	f, _ := r.Read()
+	if _, err := f.SingleGenotype("NA00001", r.Header.Genotypes); err != nil {
+		t.Fatalf("SingleGenotype() error: %v", err)
+	}
+
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteSitesOnlyHeader(*r.Header)
+	w.WriteSitesOnly(f)
+	if got := b.String(); got != want {
+		t.Errorf("WriteSitesOnly() error:\ngot \n%v \nwant \n%v", got, want)
+	}
+	if len(f.Genotypes) != 2 || len(r.Header.Genotypes) != 2 {
+		t.Errorf("WriteSitesOnly() error: input feature or header modified")
+	}
+}
+
+func TestWriteHeaderRoundTrip(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##fileDate=20090805\n" +
+		"##INFO=<ID=NS,Number=1,Type=Integer,Description=\"Number of Samples With Data\">\n" +
+		"##source=myImputationProgramV3.1\n" +
+		"##FILTER=<ID=q10,Description=\"Quality below 10\">\n" +
+		"##reference=file:///seq/references/1000GenomesPilot-NCBI36.fasta\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"##INFO=<ID=QT,Number=1,Type=String,Description=\"A, B \\\"quoted\\\"\",Source=\"my tool\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteHeader(*r.Header)
+	if got := b.String(); got != input+"\n" {
+		t.Errorf("WriteHeader() error:\ngot \n%v \nwant \n%v", got, input+"\n")
+	}
+}
+
+func TestMeta_String(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  *Meta
+		Output string
+	}{{
+		Name:   "Quoted",
+		Input:  &Meta{FieldType: "INFO", Id: "QT", Number: "1", Type: "String", Description: `"Already quoted"`},
+		Output: `##INFO=<ID=QT,Number=1,Type=String,Description="Already quoted">`,
+	}, {
+		Name:   "Unquoted",
+		Input:  &Meta{FieldType: "INFO", Id: "QT", Number: "1", Type: "String", Description: `A, B "quoted"`},
+		Output: `##INFO=<ID=QT,Number=1,Type=String,Description="A, B \"quoted\"">`,
+	}, {
+		Name:   "Optional",
+		Input:  &Meta{FieldType: "INFO", Id: "QT", Description: "Plain", Optional: map[string]string{"Version": "3", "Source": "my tool"}},
+		Output: `##INFO=<ID=QT,Description="Plain",Source="my tool",Version=3>`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := tt.Input.String(); got != tt.Output {
+				t.Errorf("String() error:\ngot \t%v\nwant \t%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestWriteAll(t *testing.T) {
+	h := NewHeader()
+	h.FileFormat = "VCFv4.2"
+	f := Feature{
+		Chrom:      "20",
+		Pos:        14370,
+		Id:         "trs6054257",
+		Ref:        "G",
+		Alt:        []string{"A"},
+		Qual:       29,
+		QualFormat: 'f',
+		Filter:     "PASS",
+		Info:       map[string]string{"NS": "3"},
+		InfoOrder:  map[string]int{"NS": 0},
+	}
+
+	tests := []struct {
+		Name   string
+		Input  []*Feature
+		Output string
+	}{{
+		Name:   "NoFeatures",
+		Output: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
+	}, {
+		Name:   "SingleFeature",
+		Input:  []*Feature{&f},
+		Output: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n20\t14370\ttrs6054257\tG\tA\t29\tPASS\tNS=3\n",
+	}, {
+		Name:   "MultipleFeatures",
+		Input:  []*Feature{&f, &f},
+		Output: "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n20\t14370\ttrs6054257\tG\tA\t29\tPASS\tNS=3\n20\t14370\ttrs6054257\tG\tA\t29\tPASS\tNS=3\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			w.WriteAll(tt.Input, h)
+			if got := b.String(); got != tt.Output {
+				t.Errorf("WriteAll() error:\ngot \n%v \nwant \n%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestCopy(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=NS,Number=1,Type=Integer,Description=\"Number of Samples With Data\">\n" +
+		"##INFO=<ID=DB,Number=0,Type=Flag,Description=\"dbSNP membership\">\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3;DB\tGT\t0|0\t1|0\n" +
+		"20\t17330\t.\tT\tA\t3\tq10\tNS=3\tGT\t0|0\t0|1\n" +
+		"20\t1110696\trs6040355\tA\tG,T\t67\tPASS\t.\tGT\t1|2\t2|1\n"
+
+	tests := []struct {
+		Name   string
+		Writer io.Writer
+		Output string
+		Error  error
+	}{{
+		Name:   "Copy",
+		Writer: &bytes.Buffer{},
+		Output: input,
+	}, {
+		Name:   "WriteError",
+		Writer: errWriter{},
+		Error:  errors.New("disk full"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			w, _ := NewWriter(tt.Writer)
+			if err := Copy(w, r, nil); !reflect.DeepEqual(err, tt.Error) {
+				t.Errorf("Copy() error: unexpected error\ngot \t%v\nwant \t%v", err, tt.Error)
+			}
+			if b, ok := tt.Writer.(*bytes.Buffer); ok && b.String() != tt.Output {
+				t.Errorf("Copy() error:\ngot \n%v \nwant \n%v", b.String(), tt.Output)
+			}
+		})
+	}
+}
+
+func TestWriter_CRLF(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3;DB\tGT\t0|0\t1|0\n" +
+		"20\t17330\t.\tT\tA\t3\tq10\tNS=3\tGT\t0|0\t0|1\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	features, err := r.ReadAll()
+	if err != io.EOF {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.CRLF = true
+	w.WriteAll(features, r.Header)
+
+	want := strings.ReplaceAll(input, "\n", "\r\n")
+	if b.String() != want {
+		t.Fatalf("WriteAll() error: CRLF\ngot \t%q\nwant \t%q", b.String(), want)
+	}
+
+	// read back, the reader accepts CRLF line endings
+	r, err = NewReader(&b)
+	if err != nil {
+		t.Fatalf("NewReader() error: CRLF: %v", err)
+	}
+	roundTrip, err := r.ReadAll()
+	if err != io.EOF {
+		t.Fatalf("ReadAll() error: CRLF: %v", err)
+	}
+	var out bytes.Buffer
+	w, _ = NewWriter(&out)
+	w.WriteAll(roundTrip, r.Header)
+	if out.String() != input {
+		t.Errorf("ReadAll() error: CRLF round trip\ngot \t%q\nwant \t%q", out.String(), input)
+	}
+}
+
+func TestHeader_String(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"##source=myImputationProgramV3.1\n" +
+		"##INFO=<ID=NS,Number=1,Type=Integer,Description=\"Number of Samples With Data\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteHeader(*r.Header)
+	if got := r.Header.String(); got != b.String() || got != input {
+		t.Errorf("String() error: differs from WriteHeader()\ngot \n%v \nwriter \n%v \nwant \n%v", got, b.String(), input)
+	}
+
+	// metas missing from MetaOrder are written after the others
+	r.Header.PrintOrder = append(r.Header.PrintOrder, &Meta{FieldType: "FILTER", Id: "q10", Description: `"Quality below 10"`, FieldOrder: []string{"ID", "Description"}})
+	want := strings.Replace(input, "#CHROM", "##FILTER=<ID=q10,Description=\"Quality below 10\">\n#CHROM", 1)
+	if got := r.Header.String(); got != want {
+		t.Errorf("String() error: unlisted meta\ngot \n%v \nwant \n%v", got, want)
+	}
+}
+
+func TestOpenWriter(t *testing.T) {
+	input := "##fileformat=VCFv4.2\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"20\t14370\trs6054257\tG\tA\t29\tPASS\tNS=3\n"
+
+	for _, file := range []string{"out.vcf", "out.vcf.gz", "out.vcf.bgz"} {
+		t.Run(file, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			features, _ := r.ReadAll()
+
+			path := filepath.Join(t.TempDir(), file)
+			w, err := OpenWriter(path)
+			if err != nil {
+				t.Fatalf("OpenWriter() error: %v", err)
+			}
+			w.WriteAll(features, r.Header)
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			or, err := OpenReader(path)
+			if err != nil {
+				t.Fatalf("OpenReader() error: %v", err)
+			}
+			defer or.Close()
+			got, err := or.ReadAll()
+			if err != io.EOF && err != nil {
+				t.Fatalf("ReadAll() error: %v", err)
+			}
+			var b bytes.Buffer
+			bw, _ := NewWriter(&b)
+			bw.WriteAll(got, or.Header)
+			if b.String() != input {
+				t.Errorf("OpenReader() error:\ngot \n%v \nwant \n%v", b.String(), input)
+			}
+		})
+	}
+}
+
+func TestOpenReader_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.vcf")
+	_ = os.WriteFile(path, []byte("##INFO=<ID=NS\n"), 0644)
+	if _, err := OpenReader(path); err == nil {
+		t.Errorf("OpenReader() error: expected error for invalid header")
+	}
+}
+
+func TestOpenReader_Multistream(t *testing.T) {
+	// gzip members split mid-record, as bgzf blocks are
+	members := []string{
+		"##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n20\t14370\t.\tG\tA\t29\tPA",
+		"SS\tNS=3\n20\t17330\t.\tT\tA\t3\tq10\tNS=3\n",
+		"20\t1110696\t.\tA\tG\t67\tPASS\tNS=2\n",
+	}
+	var b bytes.Buffer
+	for _, member := range members {
+		gz := gzip.NewWriter(&b)
+		_, _ = gz.Write([]byte(member))
+		_ = gz.Close()
+	}
+	path := filepath.Join(t.TempDir(), "multi.vcf.gz")
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenReader(path)
+	if err != nil {
+		t.Fatalf("OpenReader() error: %v", err)
+	}
+	defer r.Close()
+	features, err := r.ReadAllFeatures()
+	if err != nil || len(features) != 3 {
+		t.Errorf("ReadAllFeatures() error: not every member read\ngot \t%d %v\nwant \t%d", len(features), err, 3)
+	}
+}
+
+func TestWriteFeature_SetField(t *testing.T) {
+	line := "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ:DP\t0|0:48:1\t1|0:48:8\n"
+	tests := []struct {
+		Name   string
+		Input  string
+		Sample string
+		Key    string
+		Value  string
+		Output string
+	}{{
+		Name:   "ExistingField",
+		Sample: "NA00002",
+		Key:    "DP",
+		Value:  "12",
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ:DP\t0|0:48:1\t1|0:48:12\n",
+	}, {
+		Name:   "GT",
+		Sample: "NA00001",
+		Key:    "GT",
+		Value:  "0/1",
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ:DP\t0/1:48:1\t1|0:48:8\n",
+	}, {
+		Name:   "NewField",
+		Sample: "NA00001",
+		Key:    "HQ",
+		Value:  "51",
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:GQ:DP:HQ\t0|0:48:1:51\t1|0:48:8:.\n",
+	}, {
+		Name:   "NewGT",
+		Input:  "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tDP\t5\t8\n",
+		Sample: "NA00001",
+		Key:    "GT",
+		Value:  "0/1",
+		Output: "20\t14370\t.\tG\tA\t29\tPASS\tNS=3\tGT:DP\t0/1:5\t.:8\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			input := line
+			if tt.Input != "" {
+				input = tt.Input
+			}
+			r, err := NewReader(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tNA00001\tNA00002\n" + input))
+			if err != nil {
+				t.Fatalf("NewReader() error: %v", err)
+			}
+			f, _ := r.Read()
+			format := make(map[string]int, len(f.Format))
+			for key, i := range f.Format {
+				format[key] = i
+			}
+			genotypes := append([][]byte(nil), f.Genotypes...)
+			gt, err := f.SingleGenotype(tt.Sample, r.Header.Genotypes)
+			if err != nil {
+				t.Fatalf("SingleGenotype() error: %v", err)
+			}
+			gt.SetField(tt.Key, tt.Value)
+
+			var b bytes.Buffer
+			w, _ := NewWriter(&b)
+			w.WriteFeature(f)
+			if got := b.String(); got != tt.Output {
+				t.Errorf("WriteFeature() error:\ngot \n%v \nwant \n%v", got, tt.Output)
+			}
+			if !reflect.DeepEqual(f.Format, format) || !reflect.DeepEqual(f.Genotypes, genotypes) {
+				t.Errorf("WriteFeature() error: feature changed\ngot \t%v %q\nwant \t%v %q", f.Format, f.Genotypes, format, genotypes)
+			}
+
+			// syncing gives the same line
+			f.SyncGenotypes()
+			b.Reset()
+			w.WriteFeature(f)
+			if got := b.String(); got != tt.Output {
+				t.Errorf("SyncGenotypes() error:\ngot \n%v \nwant \n%v", got, tt.Output)
+			}
+		})
+	}
+}
+
+func TestWriteFeature_Flags(t *testing.T) {
+	input := "20\t14370\t.\tG\tA\t29\tPASS\tDB;FOO=FOO;NS=3\n"
+	r, err := NewReader(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" + input))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	f, _ := r.Read()
+	if !f.IsFlag("DB") || f.IsFlag("FOO") || f.IsFlag("NS") {
+		t.Errorf("IsFlag() error: got DB=%v FOO=%v NS=%v", f.IsFlag("DB"), f.IsFlag("FOO"), f.IsFlag("NS"))
+	}
+
+	var b bytes.Buffer
+	w, _ := NewWriter(&b)
+	w.WriteFeature(f)
+	if got := b.String(); got != input {
+		t.Errorf("WriteFeature() error:\ngot \n%v \nwant \n%v", got, input)
+	}
+}
+
+func TestWriteFeature_SortedInfo(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "sorted_info.golden.vcf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(reorder bool) []byte {
+		r, err := OpenReader(filepath.Join("testdata", "sorted_info.vcf"))
+		if err != nil {
+			t.Fatalf("OpenReader() error: %v", err)
+		}
+		defer r.Close()
+		features, _ := r.ReadAllFeatures()
+
+		var b bytes.Buffer
+		w, _ := NewWriter(&b)
+		w.SortedInfo = true
+		w.WriteHeader(*r.Header)
+		for _, f := range features {
+			if reorder { // the same fields, added in reverse order
+				order := make([]string, len(f.InfoOrder))
+				for key, i := range f.InfoOrder {
+					order[len(order)-1-i] = key
+				}
+				rebuilt := *f
+				rebuilt.Info, rebuilt.InfoOrder, rebuilt.Flags = nil, nil, nil
+				for _, key := range order {
+					if f.IsFlag(key) {
+						rebuilt.SetFlag(key)
+					} else {
+						rebuilt.AddInfo(key, f.Info[key])
+					}
+				}
+				f = &rebuilt
+			}
+			w.WriteFeature(f)
+		}
+		return b.Bytes()
+	}
+
+	for _, reorder := range []bool{false, true} {
+		if got := write(reorder); !bytes.Equal(got, want) {
+			t.Errorf("WriteFeature() error: reordered %v\ngot \n%s \nwant \n%s", reorder, got, want)
+		}
+	}
+}